@@ -26,33 +26,19 @@ import (
 // returns in order of most recently created.
 // converted to page through the results, allows us to do an empty search for customers
 func (this *HouseCall) SearchCustomers (ctx context.Context, token, search string) ([]Customer, error) {
-    header := make(map[string]string)
-    header["Authorization"] = "Bearer " + token 
-
-    params := url.Values{}
-    params.Set("page_size", "200")
-    params.Set("sort_direction", "desc")
-    params.Set("sort_by", "created_at")
-    params.Set("q", search)
-
     var ret []Customer
 
-    for i := 1; i <= 10; i++ { // stay in a loop as long as we're pulling customers, but put a limit
-        params.Set("page", fmt.Sprintf("%d", i)) // set our next page
-
-        resp := customerListResponse{}
-    
-        errObj, err := this.send (ctx, http.MethodGet, fmt.Sprintf("customers?%s", params.Encode()), header, nil, &resp)
-        if err != nil { return nil, errors.WithStack(err) } // bail
-        if errObj != nil { return nil, errObj.Err(search) } // something else bad
-
-        // we're here, we're good
-        ret = append (ret, resp.Customers...)
-        
-        if i >= resp.TotalPages { return ret, nil } // we finished
+    // put a limit, more than 2k customers and you should be using IterateCustomers
+    iter := this.IterateCustomers (ctx, token, search, PageOptions { Max: 2000, Workers: defaultPageWorkers })
+    for iter.Next() {
+        ret = append (ret, iter.Item())
+    }
+    if iter.Err() != nil {
+        if tooMany (iter.Err()) { return ret, errors.Wrap (iter.Err(), search) } // still give them what we got
+        return nil, errors.Wrap (iter.Err(), search)
     }
-    // this only happens if we have too many pages... meaning more than 2k customers
-    return ret, ErrTooManyRecords 
+
+    return ret, nil // we finished
 }
 
 // used to request a specific page for customers
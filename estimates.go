@@ -13,7 +13,6 @@ import (
     
     "fmt"
     "net/http"
-    "net/url"
     "context"
     "time"
 )
@@ -22,72 +21,59 @@ import (
  //----- PRIVATE FUNCTIONS -----------------------------------------------------------------------------------------------//
 //-----------------------------------------------------------------------------------------------------------------------//
 
+// pulls all the estimates from the iterator
+func estimateList (iter *EstimateIterator) ([]Estimate, error) {
+    ret := make([]Estimate, 0) // main list to return
+
+    for iter.Next() {
+        ret = append (ret, iter.Item())
+    }
+    if iter.Err() != nil {
+        if tooMany (iter.Err()) { return ret, iter.Err() } // still give them what we got
+        return nil, iter.Err()
+    }
+
+    return ret, nil // we finished
+}
+
   //-----------------------------------------------------------------------------------------------------------------------//
  //----- FUNCTIONS -------------------------------------------------------------------------------------------------------//
 //-----------------------------------------------------------------------------------------------------------------------//
 
+// returns all the estimates matching our filters
+// if there's more than opts.Max, this returns the first Max estimates along with ErrTooManyRecords
+func (this *HouseCall) Estimates (ctx context.Context, token string, opts ListEstimatesOptions) ([]Estimate, error) {
+    return estimateList (this.IterateEstimates (ctx, token, opts))
+}
+
 // Returns a list of the estimates that are marked as "unscheduled".
+// if there's more than pageLimit pages of them, this returns ErrTooManyRecords without any estimates, ~3 seconds per page request adds up
 func (this *HouseCall) ListUnscheduledEstimates (ctx context.Context, token string, pageLimit int) ([]Estimate, error) {
-    ret := make([]Estimate, 0) // main list to return
-    header := make(map[string]string)
-    header["Authorization"] = "Bearer " + token 
+    if pageLimit == 0 { pageLimit = 1 } // just to make it work
 
-    params := url.Values{}
-    params.Set("page_size", "200")
-    params.Set("work_status[]", "unscheduled")
+    iter := this.IterateEstimates (ctx, token, ListEstimatesOptions {
+        PageOptions: PageOptions { Max: pageLimit * 200 },
+        WorkStatuses: []WorkStatusFilter{ WorkStatusFilter_unscheduled },
+    })
+    iter.checkTotal = true // some of them isn't any use, so don't bother requesting them
 
-    if pageLimit == 0 { pageLimit = 1 } // just to make it work
-    
-    for i := 1; i <= pageLimit; i++ { // stay in a loop as long as we're pulling estimates
-        params.Set("page", fmt.Sprintf("%d", i)) // set our next page
-        resp := estimateListResponse{}
-        
-        errObj, err := this.send (ctx, http.MethodGet, fmt.Sprintf("estimates?%s", params.Encode()), header, nil, &resp)
-        if err != nil { return nil, errors.WithStack(err) } // bail
-        if errObj != nil { return nil, errObj.Err("") } // something else bad
-
-        // see if we have too many
-        if resp.TotalPages > pageLimit {
-            return nil, nil // we have too many pages and it would take too long to return them all, ~3 seconds per page request
-        }
-
-        // we're here, we're good
-        ret = append (ret, resp.Estimates...)
-
-        if i >= resp.TotalPages { return ret, nil } // we finished
-    }
-    return ret, nil // we're done
+    return estimateList (iter)
 }
 
 // returns a list of estimates for a specific employee over the target date range
 func (this *HouseCall) ListEstimates (ctx context.Context, token string, employeeId string, start, finish time.Time) ([]Estimate, error) {
-    ret := make([]Estimate, 0) // main list to return
-    header := make(map[string]string)
-    header["Authorization"] = "Bearer " + token 
-
-    params := url.Values{}
-    params.Set("page_size", "200")
-    params.Set("sort_direction", "desc")
-    params.Set("scheduled_start_min", start.Format(time.RFC3339))
-    params.Set("scheduled_start_max", finish.Format(time.RFC3339))
-    if len(employeeId) > 0 {
-        params.Set("employee_ids[]", employeeId)
+    opts := ListEstimatesOptions {
+        PageOptions: PageOptions { Max: 20000, Workers: defaultPageWorkers },
+        ScheduledStart: TimeRange { start, finish },
     }
-    
-    for i := 1; i <= 100; i++ { // stay in a loop as long as we're pulling estimates
-        params.Set("page", fmt.Sprintf("%d", i)) // set our next page
-        resp := estimateListResponse{}
-        
-        errObj, err := this.send (ctx, http.MethodGet, fmt.Sprintf("estimates?%s", params.Encode()), header, nil, &resp)
-        if err != nil { return nil, errors.WithStack(err) } // bail
-        if errObj != nil { return nil, errObj.Err(employeeId) } // something else bad
-
-        // we're here, we're good
-        ret = append (ret, resp.Estimates...)
-        
-        if i >= resp.TotalPages { return ret, nil } // we finished
+    if len(employeeId) > 0 {
+        opts.EmployeeIds = []string{ employeeId }
     }
-    return ret, errors.Wrapf (ErrTooManyRecords, "received over %d estimates in your history", len(ret))
+
+    ret, err := this.Estimates (ctx, token, opts)
+    if err != nil { return ret, errors.Wrap (err, employeeId) } // ret is only set for ErrTooManyRecords
+
+    return ret, nil // we finished
 }
 
 // gets the info about a specific estimate
@@ -134,6 +120,25 @@ func (this *HouseCall) UpdateEstimateSchedule (ctx context.Context, token, estId
     return nil
 }
 
+// changes the employees assigned to an option in the estimate, without touching the scheduled time
+// this doesn't notify the customer
+func (this *HouseCall) DispatchEstimateOption (ctx context.Context, token, estId, optionId string, employeeIds []string) error {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
+
+    dispatch := &JobDispatch { DispatchedEmployees: make([]DispatchedEmployee, 0, len(employeeIds)) }
+    for _, id := range employeeIds {
+        dispatch.DispatchedEmployees = append (dispatch.DispatchedEmployees, DispatchedEmployee{id}) 
+    }
+
+    errObj, err := this.send (ctx, http.MethodPut, fmt.Sprintf("estimates/%s/options/%s/dispatch", estId, optionId), header, dispatch, nil)
+    if err != nil { return errors.WithStack(err) } // bail
+    if errObj != nil { return errObj.Err(estId + ":" + optionId) } // something else bad
+
+    // we're here, we're good
+    return nil
+}
+
 // creates a new estimate in the system
 func (this *HouseCall) CreateEstimate (ctx context.Context, token, customerId, addressId string, 
                                     startTime time.Time, duration, arrivalWindow time.Duration, notifyCustomer bool,
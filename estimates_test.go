@@ -3,12 +3,63 @@ package housecall
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/pkg/errors"
 
 	"testing"
 	"context"
 	"time"
+	"net/http"
+	"sync/atomic"
 )
 
+func TestFirstEstimatesOptions (t *testing.T) {
+	start := time.Date (2025, 4, 27, 6, 0, 0, 0, time.UTC)
+
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		assert.Equal (t, "/estimates", r.URL.Path)
+		assert.Equal (t, []string{"pro_1", "pro_2"}, params["employee_ids[]"])
+		assert.Equal (t, []string{"approved"}, params["approval_status[]"])
+		assert.Equal (t, []string{"scheduled"}, params["work_status[]"])
+		assert.Equal (t, "2025-04-27T06:00:00Z", params.Get ("scheduled_start_min"))
+		assert.Equal (t, "2025-04-28T06:00:00Z", params.Get ("scheduled_start_max"))
+		assert.Equal (t, "desc", params.Get ("sort_direction"))
+
+		w.Write ([]byte(`{"total_pages":1,"total_items":1,"estimates":[{"id":"est_1"}]}`))
+	})
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	ests, err := hc.Estimates (ctx, "token", ListEstimatesOptions {
+		EmployeeIds: []string{ "pro_1", "pro_2" },
+		WorkStatuses: []WorkStatusFilter{ WorkStatusFilter_scheduled },
+		ApprovalStatuses: []string{ "approved" },
+		ScheduledStart: TimeRange { start, start.AddDate (0, 0, 1) },
+	})
+	if err != nil { t.Fatal (err) }
+
+	assert.Equal (t, 1, len(ests))
+	assert.Equal (t, "est_1", ests[0].Id)
+}
+
+// used to return nil, nil
+func TestFirstEstimatesUnscheduledTooMany (t *testing.T) {
+	var calls int32
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32 (&calls, 1)
+		w.Write ([]byte(`{"total_pages":3,"total_items":500,"estimates":[{"id":"est_1"}]}`))
+	})
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	ests, err := hc.ListUnscheduledEstimates (ctx, "token", 1)
+	assert.Equal (t, ErrTooManyRecords, errors.Cause (err))
+	assert.Equal (t, 0, len(ests))
+	assert.Equal (t, int32(1), atomic.LoadInt32 (&calls), "shouldn't bother with the other pages")
+}
+
 func TestThirdEstimatesUnscheduled (t *testing.T) {
 	hc, cfg := newHouseCall (t)
 
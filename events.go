@@ -9,11 +9,6 @@
 package housecall 
 
 import (
-    "github.com/pkg/errors"
-    
-    "fmt"
-    "net/http"
-    "net/url"
     "context"
     "time"
 )
@@ -30,34 +25,22 @@ import (
 // this includes any event that overlaps the passed time
 func (this *HouseCall) ListEvents (ctx context.Context, token string, start, end time.Time) ([]Event, error) {
     ret := make([]Event, 0) // main list to return
-    header := make(map[string]string)
-    header["Authorization"] = "Bearer " + token 
-
-    params := url.Values{}
-    params.Set("page_size", "200")
-    params.Set("sort_direction", "desc")
     
-    for i := 1; i <= 10; i++ { // stay in a loop as long as we're pulling estimates
-        params.Set("page", fmt.Sprintf("%d", i)) // set our next page
-        resp := eventListResponse{}
-        
-        errObj, err := this.send (ctx, http.MethodGet, fmt.Sprintf("events?%s", params.Encode()), header, nil, &resp)
-        if err != nil { return nil, errors.WithStack(err) } // bail
-        if errObj != nil { return nil, errObj.Err("") } // something else bad
-
-        // we're here, we're good
+    iter := this.IterateEvents (ctx, token, PageOptions { Max: 2000 })
+    for iter.Next() {
         // make sure this event fits within the range
-        for _, event := range resp.Events {
-            start, end, err := event.scheduleRange()
-            if err != nil { return nil, err } // bailing hard 
+        event := iter.Item()
+        start, end, err := event.scheduleRange()
+        if err != nil { return nil, err } // bailing hard 
 
-            if start.Before(end) && end.After(start) {
-                ret = append (ret, event)
-            }
+        if start.Before(end) && end.After(start) {
+            ret = append (ret, event)
         }
-
-        if i >= resp.TotalPages { return ret, nil } // we finished
     }
-    return ret, errors.Wrapf (ErrTooManyRecords, "received over %d events in your history", len(ret))
-}
+    if iter.Err() != nil {
+        if tooMany (iter.Err()) { return ret, iter.Err() } // still give them what we got
+        return nil, iter.Err()
+    }
 
+    return ret, nil // we finished
+}
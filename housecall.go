@@ -16,9 +16,19 @@ import (
     
     //"fmt"
     "net/http"
+    "net/url"
     "context"
 )
 
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- STRUCTS ---------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// gets the results of the oauth redirect back to us
+// err is set if anything went wrong, otherwise oauth and company are filled in
+// it's up to this to write the response back to the user
+type OAuthCallback func (w http.ResponseWriter, r *http.Request, oauth *OauthResponse, company *Company, err error)
+
   //-----------------------------------------------------------------------------------------------------------------------//
  //----- PRIVATE FUNCTIONS -----------------------------------------------------------------------------------------------//
 //-----------------------------------------------------------------------------------------------------------------------//
@@ -29,6 +39,59 @@ import (
 
 //----- OUATH -------------------------------------------------------------------------------------------------------//
 
+// returns the url to send the user to so they can authorize us with HCP
+// state is passed back to the callback url, use it to prevent CSRF
+func (this *HouseCall) AuthorizeURL (state string) string {
+    params := url.Values{}
+    params.Set("response_type", "code")
+    params.Set("client_id", this.clientId)
+    params.Set("redirect_uri", this.callbackUrl)
+    if len(state) > 0 {
+        params.Set("state", state)
+    }
+
+    return this.baseURL + "/oauth/authorize?" + params.Encode()
+}
+
+// returns a handler for the callback url the user gets sent back to after authorizing us
+// validState is required, it should return true if the state is one we handed out in AuthorizeURL for this user
+// the code is exchanged for tokens and the company is looked up before calling callback
+// panics if validState or callback is nil, without checking the state anyone could connect their HCP account to your user
+func (this *HouseCall) CallbackHandler (validState func (r *http.Request, state string) bool, callback OAuthCallback) http.Handler {
+    if validState == nil { panic ("housecall: CallbackHandler requires validState to check the state param") }
+    if callback == nil { panic ("housecall: CallbackHandler requires a callback") }
+
+    return http.HandlerFunc (func (w http.ResponseWriter, r *http.Request) {
+        params := r.URL.Query()
+
+        state := params.Get("state")
+        if len(state) == 0 || !validState (r, state) {
+            callback (w, r, nil, nil, errors.WithStack(ErrInvalidState))
+            return
+        }
+
+        if len(params.Get("error")) > 0 { // they didn't authorize us
+            callback (w, r, nil, nil, errors.Wrapf (ErrInvalidCode, "%s : %s", params.Get("error"), params.Get("error_description")))
+            return
+        }
+
+        code := params.Get("code")
+        if len(code) == 0 {
+            callback (w, r, nil, nil, errors.Wrap (ErrInvalidCode, "missing code"))
+            return
+        }
+
+        oauth, err := this.TokensFromCode (r.Context(), code)
+        if err != nil {
+            callback (w, r, nil, nil, err)
+            return
+        }
+
+        company, err := this.Company (r.Context(), oauth.AccessToken)
+        callback (w, r, oauth, company, err) // we're here, we're good, unless the company failed
+    })
+}
+
 // Takes the passed code we got from the params of the redirect url and converts it to long-live token and refresh token
 func (this *HouseCall) TokensFromCode (ctx context.Context, code string) (*OauthResponse, error) {
     req := this.seedOAuth()
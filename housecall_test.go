@@ -11,6 +11,8 @@ import (
 	"encoding/json"
 	"time"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 )
 
 func newHouseCall (t *testing.T) (*HouseCall, *testConfig) {
@@ -74,3 +76,55 @@ func TestSecondHouseCallRefreshBad (t *testing.T) {
 	assert.Equal (t, ErrInvalidCode, errors.Cause(err))
 }
 
+
+func TestFirstHouseCallAuthorizeURL (t *testing.T) {
+	hc, err := NewHouseCall ("ca96e4cd990507c2995b9633bd9caa679bee26e99f98572ba54751ab4ff24886", 
+		"1fd00f12ab1d3d13c6bf746aa1868bd591af098100d800195b56b6fa97795d73", "https://google.com")
+	if err != nil { t.Fatal (err) }
+
+	assert.Equal (t, "https://api.housecallpro.com/oauth/authorize?client_id=ca96e4cd990507c2995b9633bd9caa679bee26e99f98572ba54751ab4ff24886&redirect_uri=https%3A%2F%2Fgoogle.com&response_type=code&state=abc123", 
+		hc.AuthorizeURL ("abc123"))
+}
+
+func TestFirstHouseCallCallbackHandler (t *testing.T) {
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/token":
+			w.Write ([]byte(`{"access_token":"access1","refresh_token":"refresh1","expires_in":7200,"created_at":1619557886}`))
+		case "/company":
+			assert.Equal (t, "Bearer access1", r.Header.Get ("Authorization"))
+			w.Write ([]byte(companyTest1))
+		default:
+			t.Errorf ("unexpected request : %s", r.URL.Path)
+		}
+	})
+
+	var gotOauth *OauthResponse
+	var gotCompany *Company
+	var gotErr error
+
+	handler := hc.CallbackHandler (func (r *http.Request, state string) bool { return state == "abc123" },
+		func (w http.ResponseWriter, r *http.Request, oauth *OauthResponse, company *Company, err error) {
+			gotOauth, gotCompany, gotErr = oauth, company, err
+		})
+
+	// bad state, shouldn't even try the code
+	handler.ServeHTTP (httptest.NewRecorder(), httptest.NewRequest (http.MethodGet, "/housecall?code=code1&state=wrong", nil))
+	assert.Equal (t, ErrInvalidState, errors.Cause (gotErr))
+
+	// no state at all, never makes it to validState
+	handler.ServeHTTP (httptest.NewRecorder(), httptest.NewRequest (http.MethodGet, "/housecall?code=code1", nil))
+	assert.Equal (t, ErrInvalidState, errors.Cause (gotErr))
+
+	// no code
+	handler.ServeHTTP (httptest.NewRecorder(), httptest.NewRequest (http.MethodGet, "/housecall?state=abc123", nil))
+	assert.Equal (t, ErrInvalidCode, errors.Cause (gotErr))
+
+	handler.ServeHTTP (httptest.NewRecorder(), httptest.NewRequest (http.MethodGet, "/housecall?code=code1&state=abc123", nil))
+	if gotErr != nil { t.Fatal (gotErr) }
+
+	assert.Equal (t, "refresh1", gotOauth.RefreshToken)
+	assert.Equal (t, "Comrade Brewing Company", gotCompany.Name)
+
+	assert.Panics (t, func () { hc.CallbackHandler (nil, func (http.ResponseWriter, *http.Request, *OauthResponse, *Company, error) {}) }, "state has to be checked")
+}
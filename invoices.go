@@ -0,0 +1,107 @@
+/** ****************************************************************************************************************** **
+	Calls related to invoices
+
+    Job.Invoice is just the invoice number, these get the invoice itself with its line items and payments.
+    The PDF is streamed back as it downloads, make sure to close it.
+
+    Payments only come from the invoices, a job's payments are the ones from all of its invoices.
+** ****************************************************************************************************************** **/
+
+package housecall 
+
+import (
+    "github.com/pkg/errors"
+    
+    "fmt"
+    "io"
+    "net/http"
+    "context"
+)
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- PRIVATE FUNCTIONS -----------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// returns the payments for the invoice, making sure they know which invoice they're for
+func invoicePayments (invoice Invoice) []Payment {
+    ret := make([]Payment, 0, len(invoice.Payments))
+    for _, pay := range invoice.Payments {
+        if len(pay.InvoiceId) == 0 { pay.InvoiceId = invoice.Id }
+        ret = append (ret, pay)
+    }
+    return ret
+}
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- FUNCTIONS -------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// returns all the invoices matching our filters
+func (this *HouseCall) ListInvoices (ctx context.Context, token string, opts ListInvoicesOptions) ([]Invoice, error) {
+    ret := make([]Invoice, 0) // main list to return
+
+    iter := this.IterateInvoices (ctx, token, opts)
+    for iter.Next() {
+        ret = append (ret, iter.Item())
+    }
+    if iter.Err() != nil {
+        if tooMany (iter.Err()) { return ret, iter.Err() } // still give them what we got
+        return nil, iter.Err()
+    }
+
+    return ret, nil // we're good
+}
+
+// gets the info about a specific invoice
+func (this *HouseCall) GetInvoice (ctx context.Context, token, invoiceId string) (*Invoice, error) {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
+
+    invoice := &Invoice{}
+    
+    errObj, err := this.send (ctx, http.MethodGet, fmt.Sprintf("invoices/%s", invoiceId), header, nil, invoice)
+    if err != nil { return nil, errors.WithStack(err) } // bail
+    if errObj != nil { return nil, errObj.Err(invoiceId) } // something else bad
+
+    // we're here, we're good
+    return invoice, nil
+}
+
+// returns the pdf of the invoice as it's downloaded, make sure to close it when you're done
+func (this *HouseCall) DownloadInvoicePDF (ctx context.Context, token, invoiceId string) (io.ReadCloser, error) {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
+    header["Accept"] = "application/pdf"
+
+    body, errObj, err := this.open (ctx, fmt.Sprintf("invoices/%s/pdf", invoiceId), header)
+    if err != nil { return nil, errors.WithStack(err) } // bail
+    if errObj != nil { return nil, errObj.Err(invoiceId) } // something else bad
+
+    // we're here, we're good
+    return body, nil
+}
+
+// returns the payments for all the invoices on the job
+func (this *HouseCall) ListJobPayments (ctx context.Context, token, jobId string) ([]Payment, error) {
+    ret := make([]Payment, 0) // main list to return
+
+    // can't imagine a job having more than a page of invoices
+    iter := this.IterateInvoices (ctx, token, ListInvoicesOptions { JobId: jobId, PageOptions: PageOptions { Max: 200 } })
+    for iter.Next() {
+        ret = append (ret, invoicePayments (iter.Item())...)
+    }
+    if iter.Err() != nil {
+        if tooMany (iter.Err()) { return ret, errors.Wrap (iter.Err(), jobId) } // still give them what we got
+        return nil, errors.Wrap (iter.Err(), jobId)
+    }
+
+    return ret, nil // we're good
+}
+
+// returns the payments for the invoice
+func (this *HouseCall) ListInvoicePayments (ctx context.Context, token, invoiceId string) ([]Payment, error) {
+    invoice, err := this.GetInvoice (ctx, token, invoiceId)
+    if err != nil { return nil, err }
+
+    return invoicePayments (*invoice), nil
+}
@@ -0,0 +1,110 @@
+package housecall 
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/pkg/errors"
+
+	"testing"
+	"context"
+	"time"
+	"net/http"
+	"io/ioutil"
+)
+
+const invoiceJson = `{"id":"inv_1","invoice_number":"1042","job_id":"job_1","status":"open","amount":25000,"subtotal":25000,"due_amount":15000,
+	"invoice_date":"2025-04-27T00:00:00Z","due_at":"2025-05-27T00:00:00Z",
+	"items":[{"id":"li_1","name":"Tune up","unit_price":25000,"quantity":1,"kind":"labor"}],
+	"payments":[{"id":"pay_1","amount":10000,"payment_method":"check","status":"succeeded","paid_at":"2025-04-28T15:00:00Z","reference":"4431"},
+		{"id":"pay_2","amount":5000,"payment_method":"credit card","status":"failed","paid_at":"2025-04-29T15:00:00Z"}]}`
+
+func TestFirstInvoices (t *testing.T) {
+	start := time.Date (2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/invoices":
+			params := r.URL.Query()
+			assert.Equal (t, []string{"open", "pending_payment"}, params["status[]"])
+			assert.Equal (t, "2025-04-01T00:00:00Z", params.Get ("invoice_date_min"))
+			assert.Equal (t, "2025-05-01T00:00:00Z", params.Get ("invoice_date_max"))
+			assert.Equal (t, "desc", params.Get ("sort_direction"))
+
+			w.Write ([]byte(`{"page":1,"total_pages":1,"total_items":1,"invoices":[` + invoiceJson + `]}`))
+		case "/invoices/inv_1":
+			w.Write ([]byte(invoiceJson))
+		case "/invoices/inv_1/pdf":
+			assert.Equal (t, "application/pdf", r.Header.Get ("Accept"))
+			w.Write ([]byte(`%PDF-1.4`))
+		default:
+			w.WriteHeader (http.StatusNotFound)
+		}
+	}, WithRetryPolicy (RetryPolicy{}))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	invoices, err := hc.ListInvoices (ctx, "token", ListInvoicesOptions {
+		Statuses: []InvoiceStatus{ InvoiceStatus_open, InvoiceStatus_pendingPayment },
+		InvoiceDate: TimeRange { start, start.AddDate (0, 1, 0) },
+	})
+	if err != nil { t.Fatal (err) }
+	assert.Equal (t, 1, len(invoices))
+
+	invoice, err := hc.GetInvoice (ctx, "token", "inv_1")
+	if err != nil { t.Fatal (err) }
+	assert.Equal (t, "1042", invoice.InvoiceNumber)
+	assert.Equal (t, InvoiceStatus_open, invoice.Status)
+	assert.Equal (t, Money(15000), invoice.DueAmount)
+	assert.Equal (t, time.Date (2025, 5, 27, 0, 0, 0, 0, time.UTC), invoice.DueAt.UTC())
+	assert.Equal (t, 1, len(invoice.Items))
+	if assert.Equal (t, 2, len(invoice.Payments)) {
+		assert.Equal (t, Money(10000), invoice.Payments[0].Amount)
+	}
+
+	pdf, err := hc.DownloadInvoicePDF (ctx, "token", "inv_1")
+	if err != nil { t.Fatal (err) }
+	data, _ := ioutil.ReadAll (pdf)
+	pdf.Close()
+	assert.Equal (t, "%PDF-1.4", string(data))
+
+	_, err = hc.GetInvoice (ctx, "token", "inv_2")
+	assert.Equal (t, true, errors.Is (err, ErrNotFound))
+}
+
+func TestFirstInvoicesPayments (t *testing.T) {
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/invoices":
+			assert.Equal (t, "job_1", r.URL.Query().Get ("job_id"))
+			w.Write ([]byte(`{"page":1,"total_pages":1,"total_items":2,"invoices":[` + invoiceJson + `,{"id":"inv_2","amount":5000,"payments":[{"id":"pay_3","amount":5000}]}]}`))
+		case "/invoices/inv_1":
+			w.Write ([]byte(invoiceJson))
+		}
+	})
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	payments, err := hc.ListInvoicePayments (ctx, "token", "inv_1")
+	if err != nil { t.Fatal (err) }
+	if assert.Equal (t, 2, len(payments)) {
+		assert.Equal (t, "inv_1", payments[0].InvoiceId)
+		assert.Equal (t, "check", payments[0].Method)
+		assert.Equal (t, "4431", payments[0].Reference)
+		assert.Equal (t, PaymentStatus_failed, payments[1].Status)
+	}
+
+	payments, err = hc.ListJobPayments (ctx, "token", "job_1")
+	if err != nil { t.Fatal (err) }
+	assert.Equal (t, 3, len(payments))
+
+	// the failed payment doesn't count
+	job := &Job { Total: 30000, Balance: 15000 }
+	check := job.ReconcileBalance (payments)
+	assert.Equal (t, Money(15000), check.Collected)
+	assert.Equal (t, Money(15000), check.Expected)
+	assert.Equal (t, false, check.Mismatch())
+
+	job.Balance = 10000 // hcp counted the failed one
+	assert.Equal (t, true, job.ReconcileBalance (payments).Mismatch())
+}
@@ -15,29 +15,114 @@ import (
     "net/http"
     "net/url"
     "context"
-    "time"
+    "io"
     "strings"
+    "sync"
+    "time"
 )
 
   //-----------------------------------------------------------------------------------------------------------------------//
  //----- PRIVATE FUNCTIONS -----------------------------------------------------------------------------------------------//
 //-----------------------------------------------------------------------------------------------------------------------//
 
+// pulls all the jobs from the iterator, splitting them into one job per appointment within our start and end
+// existingApps is so we don't include the same appointment twice
+func appendExpandedJobs (ret []*Job, existingApps map[string]struct{}, iter *JobIterator, start, end time.Time) ([]*Job, error) {
+    for iter.Next() {
+        for _, j := range expandJob (iter.Item(), start, end) {
+            
+            if len(j.Schedule.Appointments) > 0 {
+                if _, exists := existingApps[j.Schedule.Appointments[0].Id]; exists { continue } // skip this one
+
+                existingApps[j.Schedule.Appointments[0].Id] = struct{}{} // mark it for next time
+            }
+
+            ret = append (ret, j) // include this one
+        }
+    }
+    return ret, iter.Err()
+}
+
+// sends the request for moving a job or appointment to its next work status
+func (this *HouseCall) transition (ctx context.Context, token, link, additional string) error {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
+
+    errObj, err := this.send (ctx, http.MethodPost, link, header, nil, nil)
+    if err != nil { return errors.WithStack(err) } // bail
+    if errObj != nil { return errObj.Err(additional) } // something else bad
+
+    return nil
+}
+
+// for appointments and deletes, something that's already gone is no big deal
+// i'm also getting HouseCall Error : 400 : Archived job :
+// which happens when someone deletes the job and not just the appointment for the job
+// ErrArchived is also an ErrGone so this catches both
+func ignoreGone (err error) error {
+    if errors.Is (err, ErrGone) || errors.Is (err, ErrNotFound) { return nil }
+    return err
+}
+
+// returns the tags with add included and remove taken out, and if that's any different than what we started with
+func changeTags (tags, add, remove []string) ([]string, bool) {
+    skip := make(map[string]struct{}) // removed, or already included
+    for _, tag := range remove {
+        skip[tag] = struct{}{}
+    }
+
+    ret := make([]string, 0, len(tags) + len(add))
+    for _, tag := range append (append ([]string{}, tags...), add...) {
+        if _, ok := skip[tag]; ok { continue }
+
+        skip[tag] = struct{}{}
+        ret = append (ret, tag)
+    }
+
+    if len(ret) != len(tags) { return ret, true }
+    for i := range ret {
+        if ret[i] != tags[i] { return ret, true }
+    }
+    return ret, false // nothing changed
+}
+
+// pulls all the jobs from the iterator
+func jobList (iter *JobIterator) ([]*Job, error) {
+    ret := make([]*Job, 0) // main list to return
+
+    for iter.Next() {
+        ret = append (ret, iter.Item())
+    }
+    if iter.Err() != nil {
+        if tooMany (iter.Err()) { return ret, iter.Err() } // still give them what we got
+        return nil, iter.Err()
+    }
+
+    return ret, nil // we're good
+}
+
   //-----------------------------------------------------------------------------------------------------------------------//
  //----- FUNCTIONS -------------------------------------------------------------------------------------------------------//
 //-----------------------------------------------------------------------------------------------------------------------//
 
-// gets the info about a specific job
+// gets the info about a specific job, including its appointments
 func (this *HouseCall) GetJob (ctx context.Context, token, jobId string) (*Job, error) {
+    return this.getJob (ctx, token, jobId, "appointments")
+}
+
+// gets the job with HCP filling in the expand fields, which it leaves out otherwise
+func (this *HouseCall) getJob (ctx context.Context, token, jobId string, expand ...string) (*Job, error) {
     header := make(map[string]string)
     header["Authorization"] = "Bearer " + token 
 
     params := url.Values{}
-    params.Set("expand[]", "appointments")
+    for _, field := range expand {
+        params.Add("expand[]", field)
+    }
 
     job := &Job{}
     
-    errObj, err := this.send (ctx, http.MethodGet, fmt.Sprintf("jobs/%s", jobId), header, nil, job)
+    errObj, err := this.send (ctx, http.MethodGet, fmt.Sprintf("jobs/%s?%s", jobId, params.Encode()), header, nil, job)
     if err != nil { return nil, errors.WithStack(err) } // bail
     if errObj != nil { return nil, errObj.Err(jobId) } // something else bad
 
@@ -45,142 +130,57 @@ func (this *HouseCall) GetJob (ctx context.Context, token, jobId string) (*Job,
     return job, nil
 }
 
+// returns all the jobs matching our filters
+// if there's more than opts.Max, this returns the first Max jobs along with ErrTooManyRecords
+func (this *HouseCall) Jobs (ctx context.Context, token string, opts ListJobsOptions) ([]*Job, error) {
+    return jobList (this.IterateJobs (ctx, token, opts))
+}
+
 // Returns a list of the jobs that are marked as "unscheduled".
+// if there's more than pageLimit pages of them, this returns ErrTooManyRecords without any jobs, ~3 seconds per page request adds up
 func (this *HouseCall) ListUnscheduledJobs (ctx context.Context, token string, pageLimit int) ([]*Job, error) {
-    ret := make([]*Job, 0) // main list to return
-    header := make(map[string]string)
-    header["Authorization"] = "Bearer " + token 
-
-    params := url.Values{}
-    params.Set("page_size", "200")
-    params.Set("work_status[]", "unscheduled")
-    params.Set("sort_direction", "desc")
-
     if pageLimit == 0 { pageLimit = 1 } // just to make it work
-    
-    for i := 1; i <= pageLimit; i++ { // stay in a loop as long as we're pulling jobs
-        params.Set("page", fmt.Sprintf("%d", i)) // set our next page
-        resp := jobListResponse{}
-        
-        errObj, err := this.send (ctx, http.MethodGet, fmt.Sprintf("jobs?%s", params.Encode()), header, nil, &resp)
-        if err != nil { return nil, errors.WithStack(err) } // bail
-        if errObj != nil { return nil, errObj.Err("") } // something else bad
-
-        if resp.TotalPages > pageLimit {
-            return nil, nil // we have too many pages and it would take too long to return them all, ~3 seconds per page request
-        }
-
-        // we're here, we're good
-        ret = append (ret, resp.Jobs...)
 
-        if i >= resp.TotalPages { return ret, nil } // we finished
-    }
-    return ret, nil // we're done
+    iter := this.IterateJobs (ctx, token, ListJobsOptions {
+        PageOptions: PageOptions { Max: pageLimit * 200 },
+        WorkStatuses: []WorkStatusFilter{ WorkStatusFilter_unscheduled },
+    })
+    iter.checkTotal = true // some of them isn't any use, so don't bother requesting them
+    
+    return jobList (iter)
 }
 
 // returns all jobs that are within our start and finish ranges
+// a job with multiple appointments is split into one job for each appointment that overlaps our range
 func (this *HouseCall) ListJobs (ctx context.Context, token string, start, finish time.Time) ([]*Job, error) {
     ret := make([]*Job, 0) // main list to return
-    header := make(map[string]string)
-    header["Authorization"] = "Bearer " + token 
-
-    params := url.Values{}
-    params.Set("page_size", "200")
-    params.Set("sort_direction", "desc")
-    params.Set("scheduled_start_min", start.Format(time.RFC3339))
-    params.Set("scheduled_start_max", finish.Format(time.RFC3339))
-    params.Set("expand[]", "appointments")
-
     existingApps := make(map[string]struct{})
-    
-    for i := 1; i <= 10; i++ { // stay in a loop as long as we're pulling jobs
-        params.Set("page", fmt.Sprintf("%d", i)) // set our next page
-        resp := jobListResponse{}
-        
-        errObj, err := this.send (ctx, http.MethodGet, fmt.Sprintf("jobs?%s", params.Encode()), header, nil, &resp)
-        if err != nil { return nil, errors.WithStack(err) } // bail
-        if errObj != nil { return nil, errObj.Err("") } // something else bad
-
-        // jstr, _ := json.Marshal(resp)
-        // fmt.Println(string(jstr))
-
-        // we're here, we're good
-        for _, job := range resp.Jobs {
-            for _, j := range expandJob (job, start, finish) {
-                
-                if len(j.Schedule.Appointments) > 0 {
-                    if _, exists := existingApps[j.Schedule.Appointments[0].Id]; exists { continue } // skip this one
-
-                    existingApps[j.Schedule.Appointments[0].Id] = struct{}{} // mark it for next time
-                }
 
-                ret = append (ret, j) // include this one
-            }
-        }
-        
-        if i >= resp.TotalPages { break } // we finished
+    // querying by scheduled start and then again by scheduled end missed the middle appointments of multi-day jobs
+    // so we ask for anything that starts before we finish and ends after we start, which is any overlap
+    opts := ListJobsOptions {
+        PageOptions: PageOptions { Workers: defaultPageWorkers },
+        ScheduledStart: TimeRange { Max: finish },
+        ScheduledEnd: TimeRange { Min: start },
+        Expand: []string{ "appointments" },
     }
 
-    // 2025-04-27 NT started doing this as a way to get the second appointment for a job. Still doesn't find middle appointments
-    // now find the jobs that end on this date
-    params.Del("scheduled_start_min")
-    params.Del("scheduled_start_max")
-
-    params.Set("scheduled_end_min", start.Format(time.RFC3339))
-    params.Set("scheduled_end_max", finish.Format(time.RFC3339))
-
-    for i := 1; i <= 10; i++ { // stay in a loop as long as we're pulling jobs
-        params.Set("page", fmt.Sprintf("%d", i)) // set our next page
-        resp := jobListResponse{}
-        
-        errObj, err := this.send (ctx, http.MethodGet, fmt.Sprintf("jobs?%s", params.Encode()), header, nil, &resp)
-        if err != nil { return nil, errors.WithStack(err) } // bail
-        if errObj != nil { return nil, errObj.Err("") } // something else bad
-
-        // we're here, we're good
-        for _, job := range resp.Jobs {
-            for _, j := range expandJob (job, start, finish) {
-                
-                if len(j.Schedule.Appointments) > 0 {
-                    if _, exists := existingApps[j.Schedule.Appointments[0].Id]; exists { continue } // skip this one
-
-                    existingApps[j.Schedule.Appointments[0].Id] = struct{}{} // mark it for next time
-                }
-
-                ret = append (ret, j) // include this one
-            }
-        }
-        
-        if i >= resp.TotalPages { break } // we finished
-    }
+    ret, err := appendExpandedJobs (ret, existingApps, this.IterateJobs (ctx, token, opts), start, finish)
+    if err != nil { return nil, err }
     
     return ret, nil // we're good
 }
 
 // returns a list of jobs that are associated with the customer
 func (this *HouseCall) ListJobsFromCustomer (ctx context.Context, token string, customerId string) ([]*Job, error) {
-    ret := make([]*Job, 0, 2) // main list to return
-    header := make(map[string]string)
-    header["Authorization"] = "Bearer " + token 
+    // i can't image there beeing that many jobs for a customer and if there is i don't care about # 401
+    ret, err := this.Jobs (ctx, token, ListJobsOptions {
+        PageOptions: PageOptions { Max: 400, Workers: defaultPageWorkers },
+        CustomerId: customerId,
+    })
+    if err != nil { return ret, errors.Wrap (err, customerId) } // ret is only set for ErrTooManyRecords
 
-    params := url.Values{}
-    params.Set("page_size", "200")
-    params.Set("sort_direction", "desc")
-    params.Set("customer_id", customerId)
-    
-    for i := 1; i <= 2; i++ { // i can't image there beeing that many jobs for a customer and if there is i don't care about # 401
-        params.Set("page", fmt.Sprintf("%d", i)) // set our next page
-        resp := jobListResponse{}
-        
-        errObj, err := this.send (ctx, http.MethodGet, fmt.Sprintf("jobs?%s", params.Encode()), header, nil, &resp)
-        if err != nil { return nil, errors.WithStack(err) } // bail
-        if errObj != nil { return nil, errObj.Err(customerId) } // something else bad
-
-        // we're here, we're good
-        ret = append (ret, resp.Jobs...)
-        if i >= resp.TotalPages { return ret, nil } // we finished
-    }
-    return ret, errors.Wrapf (ErrTooManyRecords, "received over %d jobs in your history", len(ret))
+    return ret, nil // we finished
 }
 
 // updates the target scheduled time for a job
@@ -227,6 +227,234 @@ func (this *HouseCall) UpdateJobSchedule (ctx context.Context, token, jobId stri
     return nil
 }
 
+// changes the employees assigned to the job, without touching the scheduled time
+// this doesn't notify the customer
+func (this *HouseCall) DispatchJob (ctx context.Context, token, jobId string, employeeIds []string) error {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
+
+    dispatch := &JobDispatch { DispatchedEmployees: make([]DispatchedEmployee, 0, len(employeeIds)) }
+    for _, id := range employeeIds {
+        dispatch.DispatchedEmployees = append (dispatch.DispatchedEmployees, DispatchedEmployee{id}) 
+    }
+
+    errObj, err := this.send (ctx, http.MethodPut, fmt.Sprintf("jobs/%s/dispatch", jobId), header, dispatch, nil)
+    if err != nil { return errors.WithStack(err) } // bail
+    if errObj != nil { return errObj.Err(jobId) } // something else bad
+
+    // we're here, we're good
+    return nil
+}
+
+//----- TAGS
+
+// adds the tags to the job, keeping the ones it already has
+// returns the job after the changes
+func (this *HouseCall) AddJobTags (ctx context.Context, token, jobId string, tags ...string) (*Job, error) {
+    return this.UpdateJobTags (ctx, token, jobId, tags, nil)
+}
+
+// removes the tags from the job, tags the job doesn't have are ignored
+// returns the job after the changes
+func (this *HouseCall) RemoveJobTags (ctx context.Context, token, jobId string, tags ...string) (*Job, error) {
+    return this.UpdateJobTags (ctx, token, jobId, nil, tags)
+}
+
+// adds and removes tags from the job in one update
+// HCP only lets us replace all the tags, so we have to get the job first. If nothing changes we don't send the update
+// since it's a read and then a write, if someone else changes the job's tags in between, their changes are lost
+func (this *HouseCall) UpdateJobTags (ctx context.Context, token, jobId string, add, remove []string) (*Job, error) {
+    job, err := this.GetJob (ctx, token, jobId)
+    if err != nil { return nil, err }
+
+    tags, changed := changeTags (job.Tags, add, remove)
+    if !changed { return job, nil } // nothing to do
+
+    return this.UpdateJob (ctx, token, jobId, JobUpdate { Tags: &tags })
+}
+
+// adds and removes tags for all the jobs, at most workers at once
+// returns the result for every job id, nil when that job worked
+// same as UpdateJobTags, tag changes made by anyone else while this is running can be lost
+func (this *HouseCall) BatchUpdateJobTags (ctx context.Context, token string, jobIds, add, remove []string, workers int) map[string]error {
+    ret := make(map[string]error, len(jobIds))
+    var lock sync.Mutex
+    var wg sync.WaitGroup
+
+    // each job only once, otherwise 2 workers would be overwriting each other on the same job
+    unique := make([]string, 0, len(jobIds))
+    for _, jobId := range jobIds {
+        if _, exists := ret[jobId]; exists { continue }
+        ret[jobId] = nil
+        unique = append (unique, jobId)
+    }
+    jobIds = unique
+
+    if workers <= 0 { workers = defaultPageWorkers }
+    if workers > len(jobIds) { workers = len(jobIds) }
+
+    ids := make(chan string)
+    for w := 0; w < workers; w++ {
+        wg.Add (1)
+        go func () {
+            defer wg.Done()
+
+            for jobId := range ids {
+                _, err := this.UpdateJobTags (ctx, token, jobId, add, remove)
+                
+                lock.Lock()
+                ret[jobId] = err
+                lock.Unlock()
+            }
+        }()
+    }
+
+    // hand out the jobs, the ones we never get to are marked with the context's error
+    for _, jobId := range jobIds {
+        select {
+        case ids <- jobId:
+        case <-ctx.Done():
+            lock.Lock()
+            ret[jobId] = errors.WithStack (ctx.Err())
+            lock.Unlock()
+        }
+    }
+    close (ids)
+    wg.Wait()
+
+    return ret
+}
+
+//----- NOTES
+
+// returns all the notes on the job
+func (this *HouseCall) ListJobNotes (ctx context.Context, token, jobId string) ([]JobNote, error) {
+    job, err := this.GetJob (ctx, token, jobId)
+    if err != nil { return nil, err }
+
+    if job.Notes == nil { job.Notes = make([]JobNote, 0) }
+    return job.Notes, nil
+}
+
+// adds a new note to the job, without touching the ones already there
+func (this *HouseCall) CreateJobNote (ctx context.Context, token, jobId, content string) (*JobNote, error) {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
+    header["Content-Type"] = "application/json; charset=utf-8"
+
+    req := struct {
+        Content string `json:"content"`
+    }{ content }
+
+    note := &JobNote{}
+
+    errObj, err := this.send (ctx, http.MethodPost, fmt.Sprintf("jobs/%s/notes", jobId), header, req, note)
+    if err != nil { return nil, errors.WithStack(err) } // bail
+    if errObj != nil { return nil, errObj.Err(jobId) } // something else bad
+
+    // we're here, we're good
+    return note, nil
+}
+
+// removes the note from the job
+// if the job or note is already gone, we're good with that
+func (this *HouseCall) DeleteJobNote (ctx context.Context, token, jobId, noteId string) error {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
+
+    errObj, err := this.send (ctx, http.MethodDelete, fmt.Sprintf("jobs/%s/notes/%s", jobId, noteId), header, nil, nil)
+    if err != nil { return errors.WithStack(err) } // bail
+    if errObj != nil { return ignoreGone (errObj.Err(jobId)) } // something else bad, unless it's already gone
+
+    // we're here, we're good
+    return nil
+}
+
+//----- WORK STATUS
+
+// moves the job to its next work status, like when the pro is on their way or has finished
+// returns ErrInvalidTransition if the job's current status doesn't allow it, like completing a canceled job
+// returns the job after the change
+func (this *HouseCall) TransitionJob (ctx context.Context, token, jobId string, to JobTransition) (*Job, error) {
+    job, err := this.GetJob (ctx, token, jobId)
+    if err != nil { return nil, err }
+
+    if !job.WorkStatus.Allows (to) {
+        return nil, errors.Wrapf (ErrInvalidTransition, "can't %s a %s job : %s", to, job.WorkStatus, jobId)
+    }
+
+    if err = this.transition (ctx, token, fmt.Sprintf("jobs/%s/%s", jobId, to), jobId); err != nil { return nil, err }
+
+    // we're here, we're good
+    return this.GetJob (ctx, token, jobId)
+}
+
+// same as TransitionJob, but just for one of the appointments on a multi-day job
+// the appointment's status comes from the job the same way it does for ListAppointments
+func (this *HouseCall) TransitionAppointment (ctx context.Context, token, jobId, apptId string, to JobTransition) (*Job, error) {
+    job, err := this.GetJob (ctx, token, jobId)
+    if err != nil { return nil, err }
+
+    status := WorkStatus("")
+    for _, app := range job.Schedule.Appointments {
+        if app.Id != apptId { continue }
+
+        status = job.WorkStatus
+        if !job.statusApplies (app) { status = WorkStatus_scheduled } // this one hasn't been worked yet
+    }
+    if status == "" { return nil, errors.Wrapf (ErrNotFound, "appointment %s not on job %s", apptId, jobId) }
+
+    if !status.Allows (to) {
+        return nil, errors.Wrapf (ErrInvalidTransition, "can't %s a %s appointment : %s : %s", to, status, jobId, apptId)
+    }
+
+    if err = this.transition (ctx, token, fmt.Sprintf("jobs/%s/appointments/%s/%s", jobId, apptId, to), jobId + ":" + apptId); err != nil { return nil, err }
+
+    // we're here, we're good
+    return this.GetJob (ctx, token, jobId)
+}
+
+//----- ATTACHMENTS
+
+// returns all the files attached to the job
+func (this *HouseCall) ListJobAttachments (ctx context.Context, token, jobId string) ([]Attachment, error) {
+    job, err := this.getJob (ctx, token, jobId, "attachments")
+    if err != nil { return nil, err }
+
+    if job.Attachments == nil { job.Attachments = make([]Attachment, 0) }
+    return job.Attachments, nil
+}
+
+// attaches the file to the job, it's sent as it's read so large files aren't held in memory
+// this isn't retried like the other calls, since we can't read the file a second time
+func (this *HouseCall) UploadJobAttachment (ctx context.Context, token, jobId, fileName, contentType string, file io.Reader) (*Attachment, error) {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
+
+    ret := &Attachment{}
+
+    errObj, err := this.sendFile (ctx, fmt.Sprintf("jobs/%s/attachments", jobId), header, "file", fileName, contentType, file, ret)
+    if err != nil { return nil, errors.WithStack(err) } // bail
+    if errObj != nil { return nil, errObj.Err(jobId + ":" + fileName) } // something else bad
+
+    // we're here, we're good
+    return ret, nil
+}
+
+// returns the contents of the attachment as they're downloaded, make sure to close it when you're done
+// our token is only sent when the file is on HCP, and not somewhere else like a storage bucket
+func (this *HouseCall) DownloadAttachment (ctx context.Context, token string, attachment Attachment) (io.ReadCloser, error) {
+    header := make(map[string]string)
+    if strings.HasPrefix (this.url (attachment.Url), this.baseURL + "/") { header["Authorization"] = "Bearer " + token }
+
+    body, errObj, err := this.open (ctx, attachment.Url, header)
+    if err != nil { return nil, errors.WithStack(err) } // bail
+    if errObj != nil { return nil, errObj.Err(attachment.Id) } // something else bad
+
+    // we're here, we're good
+    return body, nil
+}
+
 //----- APPOINTMENTS
 
 // this is how we update the "new" setup for jobs where we have an appointment now
@@ -254,29 +482,77 @@ func (this *HouseCall) UpdateJobAppointmentSchedule (ctx context.Context, token,
     
     errObj, err := this.send (ctx, http.MethodPut, fmt.Sprintf("jobs/%s/appointments/%s", jobId, apptId), header, req, nil)
     if err != nil { return errors.WithStack(err) } // bail
-    if errObj != nil { 
-        if errObj.StatusCode == http.StatusGone || errObj.StatusCode == http.StatusNotFound {
-            return nil // no big deal
-        }
+    if errObj != nil { return ignoreGone (errObj.Err(jobId)) } // something else bad, unless it's already gone
 
-        // i'm also getting HouseCall Error : 400 : Archived job :
-        // which happens when someone deletes the job and not just the appointment for the job
-        // just going to hard code that string
-        if errObj.StatusCode == http.StatusBadRequest && strings.Contains (errObj.Err(jobId).Error(), "Archived job") {
-            return nil // also ignore these errors
-        }
-        
-        // otherwise we're good with this error here
-        return errObj.Err(jobId) // something else bad
-    }
+    // we're here, we're good
+    return nil
+}
+
+// removes the appointment from the job
+// if the job or appointment is already gone, we're good with that
+func (this *HouseCall) DeleteAppointment (ctx context.Context, token, jobId, apptId string) error {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
 
+    errObj, err := this.send (ctx, http.MethodDelete, fmt.Sprintf("jobs/%s/appointments/%s", jobId, apptId), header, nil, nil)
     if err != nil { return errors.WithStack(err) } // bail
-    if errObj != nil { return errObj.Err(jobId) } // something else bad
+    if errObj != nil { return ignoreGone (errObj.Err(jobId)) } // something else bad, unless it's already gone
 
     // we're here, we're good
     return nil
 }
 
+// returns just the appointments for the job
+// if the job is gone, then so are its appointments, so this returns an empty list
+func (this *HouseCall) ListJobAppointments (ctx context.Context, token, jobId string) ([]Appointment, error) {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
+
+    var resp struct {
+        Appointments []Appointment `json:"appointments"`
+    }
+
+    errObj, err := this.send (ctx, http.MethodGet, fmt.Sprintf("jobs/%s/appointments", jobId), header, nil, &resp)
+    if err != nil { return nil, errors.WithStack(err) } // bail
+    if errObj != nil { 
+        if err = ignoreGone (errObj.Err(jobId)); err != nil { return nil, err } // something else bad
+        return make([]Appointment, 0), nil // it's gone
+    }
+
+    // we're here, we're good
+    if resp.Appointments == nil { resp.Appointments = make([]Appointment, 0) }
+    return resp.Appointments, nil
+}
+
+// returns every appointment that overlaps our start and end, across all jobs matching our filters
+// the scheduled ranges are set from start and end, and with EmployeeIds only the appointments dispatched to one of them are included
+func (this *HouseCall) ListAppointments (ctx context.Context, token string, start, end time.Time, opts ListJobsOptions) ([]AppointmentView, error) {
+    ret := make([]AppointmentView, 0) // main list to return
+    existingApps := make(map[string]struct{})
+
+    if opts.Workers == 0 { opts.Workers = defaultPageWorkers }
+    opts.ScheduledStart = TimeRange { Max: end }
+    opts.ScheduledEnd = TimeRange { Min: start }
+    opts.Expand = []string{ "appointments" }
+
+    iter := this.IterateJobs (ctx, token, opts)
+    for iter.Next() {
+        for _, app := range jobAppointments (iter.Item(), start, end) {
+            if len(opts.EmployeeIds) > 0 && !app.hasEmployee (opts.EmployeeIds) { continue } // someone else's
+
+            if app.Id != "" {
+                if _, exists := existingApps[app.Id]; exists { continue } // skip this one
+                existingApps[app.Id] = struct{}{} // mark it for next time
+            }
+
+            ret = append (ret, app) // include this one
+        }
+    }
+    if iter.Err() != nil { return nil, iter.Err() }
+
+    return ret, nil // we're good
+}
+
 // creates a new job in the system
 func (this *HouseCall) CreateJob (ctx context.Context, token, customerId, addressId string, 
                                     startTime time.Time, duration, arrivalWindow time.Duration, 
@@ -314,6 +590,21 @@ func (this *HouseCall) CreateJob (ctx context.Context, token, customerId, addres
     return this.GetJob (ctx, token, resp.Id)
 }
 
+// changes the details for a job, only the fields set in update are sent
+// returns the job after the changes
+func (this *HouseCall) UpdateJob (ctx context.Context, token, jobId string, update JobUpdate) (*Job, error) {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
+    header["Content-Type"] = "application/json; charset=utf-8"
+
+    errObj, err := this.send (ctx, http.MethodPatch, fmt.Sprintf("jobs/%s", jobId), header, update, nil)
+    if err != nil { return nil, errors.WithStack(err) } // bail
+    if errObj != nil { return nil, errObj.Err(jobId) } // something else bad
+
+    // we're here, we're good
+    return this.GetJob (ctx, token, jobId)
+}
+
 func (this *HouseCall) CreateAppointment (ctx context.Context, token, jobId string, startTime time.Time, 
                                             duration, arrivalWindow time.Duration, employeeIds []string) (string, error) {
     header := make(map[string]string)
@@ -360,3 +651,75 @@ func (this *HouseCall) GetLineItems (ctx context.Context, token, jobId string) (
     // we're here, we're good
     return ret.Data, nil
 }
+
+// adds a line item to the end of the job's line items
+// returns the new line item, with its id
+func (this *HouseCall) CreateLineItem (ctx context.Context, token, jobId string, item LineItem) (*LineItem, error) {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
+    header["Content-Type"] = "application/json; charset=utf-8"
+
+    item.Id = "" // this is set by hcp
+    ret := &LineItem{}
+    
+    errObj, err := this.send (ctx, http.MethodPost, fmt.Sprintf("jobs/%s/line_items", jobId), header, item, ret)
+    if err != nil { return nil, errors.WithStack(err) } // bail
+    if errObj != nil { return nil, errObj.Err(jobId) } // something else bad
+
+    // we're here, we're good
+    return ret, nil
+}
+
+// changes the line item matching item.Id to match item
+func (this *HouseCall) UpdateLineItem (ctx context.Context, token, jobId string, item LineItem) (*LineItem, error) {
+    if item.Id == "" { return nil, errors.Errorf ("line item is missing its id : %s", jobId) }
+
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
+    header["Content-Type"] = "application/json; charset=utf-8"
+
+    ret := &LineItem{}
+    
+    errObj, err := this.send (ctx, http.MethodPut, fmt.Sprintf("jobs/%s/line_items/%s", jobId, item.Id), header, item, ret)
+    if err != nil { return nil, errors.WithStack(err) } // bail
+    if errObj != nil { return nil, errObj.Err(jobId + ":" + item.Id) } // something else bad
+
+    // we're here, we're good
+    return ret, nil
+}
+
+// removes the line item from the job
+// if the job or line item is already gone, we're good with that
+func (this *HouseCall) DeleteLineItem (ctx context.Context, token, jobId, itemId string) error {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
+
+    errObj, err := this.send (ctx, http.MethodDelete, fmt.Sprintf("jobs/%s/line_items/%s", jobId, itemId), header, nil, nil)
+    if err != nil { return errors.WithStack(err) } // bail
+    if errObj != nil { return ignoreGone (errObj.Err(jobId + ":" + itemId)) } // something else bad, unless it's already gone
+
+    // we're here, we're good
+    return nil
+}
+
+// replaces all the line items on the job with these in one request
+// items with an id are updated, ones without are created, and any on the job that aren't included are removed
+// returns the job's line items after the changes
+func (this *HouseCall) ReplaceLineItems (ctx context.Context, token, jobId string, items []LineItem) ([]*LineItem, error) {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token 
+    header["Content-Type"] = "application/json; charset=utf-8"
+
+    req := struct {
+        LineItems []LineItem `json:"line_items"`
+        Append bool `json:"append_line_items"`
+    }{ LineItems: items }
+    if req.LineItems == nil { req.LineItems = make([]LineItem, 0) } // this means remove all of them
+    
+    errObj, err := this.send (ctx, http.MethodPut, fmt.Sprintf("jobs/%s/line_items/bulk_update", jobId), header, req, nil)
+    if err != nil { return nil, errors.WithStack(err) } // bail
+    if errObj != nil { return nil, errObj.Err(jobId) } // something else bad
+
+    // we're here, we're good
+    return this.GetLineItems (ctx, token, jobId)
+}
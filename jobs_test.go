@@ -3,13 +3,479 @@ package housecall
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/pkg/errors"
 
 	"testing"
 	"context"
 	"time"
-	// "encoding/json"
+	"net/http"
+	"net/url"
+	"io/ioutil"
+	"strings"
+	"sync/atomic"
+	"encoding/json"
 )
 
+func TestFirstJobsOptions (t *testing.T) {
+	start := time.Date (2025, 4, 27, 6, 0, 0, 0, time.UTC)
+
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		assert.Equal (t, []string{"pro_1", "pro_2"}, params["employee_ids[]"])
+		assert.Equal (t, "cus_1", params.Get ("customer_id"))
+		assert.Equal (t, []string{"scheduled", "in_progress"}, params["work_status[]"])
+		assert.Equal (t, "2025-04-27T06:00:00Z", params.Get ("scheduled_start_min"))
+		assert.Equal (t, "", params.Get ("scheduled_start_max"), "left open")
+		assert.Equal (t, "2025-04-28T06:00:00Z", params.Get ("updated_at_max"))
+		assert.Equal (t, []string{"routed"}, params["tags[]"])
+		assert.Equal (t, "asc", params.Get ("sort_direction"))
+		assert.Equal (t, "appointments", params.Get ("expand[]"))
+		assert.Equal (t, "50", params.Get ("page_size"))
+
+		w.Write ([]byte(jobListJson))
+	})
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	jobs, err := hc.Jobs (ctx, "token", ListJobsOptions {
+		PageOptions: PageOptions { PageSize: 50 },
+		EmployeeIds: []string{ "pro_1", "pro_2" },
+		CustomerId: "cus_1",
+		WorkStatuses: []WorkStatusFilter{ WorkStatusFilter_scheduled, WorkStatusFilter_inProgress },
+		ScheduledStart: TimeRange { Min: start },
+		Updated: TimeRange { Max: start.AddDate (0, 0, 1) },
+		Tags: []string{ "routed" },
+		SortDirection: "asc",
+		Expand: []string{ "appointments" },
+	})
+	if err != nil { t.Fatal (err) }
+
+	assert.Equal (t, 6, len(jobs))
+}
+
+// returns that the request asked HCP to include the field
+func expands (r *http.Request, field string) bool {
+	for _, f := range r.URL.Query()["expand[]"] {
+		if f == field { return true }
+	}
+	return false
+}
+
+// a 3 day job, the first day was worked already and each day has a different pro
+const multiDayJobJson = `{"id":"job_1","work_status":"complete unrated",
+	"customer":{"id":"cus_1"},"address":{"id":"adr_1"},
+	"work_timestamps":{"on_my_way_at":"2025-04-27T13:30:00Z","started_at":"2025-04-27T14:00:00Z","completed_at":"2025-04-27T22:00:00Z"},
+	"assigned_employees":[{"id":"pro_1"},{"id":"pro_2"},{"id":"pro_3"}],
+	"schedule":{"scheduled_start":"2025-04-27T14:00:00Z","scheduled_end":"2025-04-29T22:00:00Z","appointments":[
+		{"id":"appt_1","start_time":"2025-04-27T14:00:00Z","end_time":"2025-04-27T22:00:00Z","dispatched_employees_ids":["pro_1"]},
+		{"id":"appt_2","start_time":"2025-04-28T14:00:00Z","end_time":"2025-04-28T22:00:00Z","dispatched_employees_ids":["pro_2"]},
+		{"id":"appt_3","start_time":"2025-04-29T14:00:00Z","end_time":"2025-04-29T22:00:00Z","dispatched_employees_ids":["pro_3"]}]}}`
+
+// a 3 day job should come back as just the middle day when that's all we ask for
+func TestFirstJobsMultiDay (t *testing.T) {
+	start := time.Date (2025, 4, 28, 0, 0, 0, 0, time.UTC)
+	finish := start.AddDate (0, 0, 1)
+
+	var params url.Values
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		params = r.URL.Query()
+		w.Write ([]byte(`{"page":1,"total_pages":1,"total_items":1,"jobs":[` + multiDayJobJson + `]}`))
+	})
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	jobs, err := hc.ListJobs (ctx, "token", start, finish)
+	if err != nil { t.Fatal (err) }
+
+	// anything that starts before we finish and ends after we start
+	assert.Equal (t, "2025-04-29T00:00:00Z", params.Get ("scheduled_start_max"))
+	assert.Equal (t, "2025-04-28T00:00:00Z", params.Get ("scheduled_end_min"))
+	assert.Equal (t, "appointments", params.Get ("expand[]"))
+
+	if assert.Equal (t, 1, len(jobs)) {
+		assert.Equal (t, "appt_2", jobs[0].Schedule.Appointments[0].Id)
+		assert.Equal (t, time.Date (2025, 4, 28, 14, 0, 0, 0, time.UTC), jobs[0].Schedule.Start.UTC())
+	}
+
+	// appointments that are only partly in our range still count
+	jobs, err = hc.ListJobs (ctx, "token", start.Add (time.Hour * 20), finish.Add (time.Hour * 20))
+	if err != nil { t.Fatal (err) }
+
+	assert.Equal (t, 2, len(jobs))
+}
+
+// the jobs for each day are full copies, changing one can't leak into the others
+func TestFirstJobsExpandCopies (t *testing.T) {
+	job := &Job{}
+	if err := json.Unmarshal ([]byte(multiDayJobJson), job); err != nil { t.Fatal (err) }
+	job.Tags = []string{ "multi" }
+	job.Customer.Tags = []string{ "vip" }
+	job.Customer.Addresses = []Address{ { Id: "adr_1" } }
+	job.Notes = []JobNote{ { Id: "note_1" } }
+
+	start := time.Date (2025, 4, 27, 0, 0, 0, 0, time.UTC)
+	jobs := expandJob (job, start, start.AddDate (0, 0, 3))
+	if !assert.Equal (t, 3, len(jobs)) { return }
+
+	jobs[0].Tags[0] = "changed"
+	jobs[0].Customer.Tags[0] = "changed"
+	jobs[0].Customer.Addresses[0].Id = "changed"
+	jobs[0].Notes[0].Id = "changed"
+	jobs[0].AssignedEmployees[0].Id = "changed"
+	jobs[0].Schedule.Appointments[0].AssignedEmployees[0] = "changed"
+
+	for _, j := range []*Job{ job, jobs[1] } {
+		assert.Equal (t, []string{ "multi" }, j.Tags)
+		assert.Equal (t, []string{ "vip" }, j.Customer.Tags)
+		assert.Equal (t, "adr_1", j.Customer.Addresses[0].Id)
+		assert.Equal (t, "note_1", j.Notes[0].Id)
+	}
+	assert.Equal (t, "pro_1", job.AssignedEmployees[0].Id)
+	assert.Equal (t, "pro_1", job.Schedule.Appointments[0].AssignedEmployees[0])
+	assert.Equal (t, "pro_2", jobs[1].AssignedEmployees[0].Id)
+}
+
+func TestFirstJobsListAppointments (t *testing.T) {
+	start := time.Date (2025, 4, 27, 0, 0, 0, 0, time.UTC)
+
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		assert.Equal (t, []string{"pro_1", "pro_2"}, r.URL.Query()["employee_ids[]"])
+		w.Write ([]byte(`{"page":1,"total_pages":1,"total_items":1,"jobs":[` + multiDayJobJson + `]}`))
+	})
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	apps, err := hc.ListAppointments (ctx, "token", start, start.AddDate (0, 0, 3), ListJobsOptions { EmployeeIds: []string{ "pro_1", "pro_2" } })
+	if err != nil { t.Fatal (err) }
+
+	if assert.Equal (t, 2, len(apps), "pro_3's day isn't included") {
+		assert.Equal (t, "appt_1", apps[0].Id)
+		assert.Equal (t, "job_1", apps[0].JobId)
+		assert.Equal (t, "cus_1", apps[0].Customer.Id)
+		assert.Equal (t, "adr_1", apps[0].Address.Id)
+		assert.Equal (t, []Employee{ { Id: "pro_1" } }, apps[0].Crew)
+		assert.Equal (t, WorkStatus_completeUnrated, apps[0].WorkStatus)
+
+		assert.Equal (t, "appt_2", apps[1].Id)
+		assert.Equal (t, []Employee{ { Id: "pro_2" } }, apps[1].Crew)
+		assert.Equal (t, WorkStatus_scheduled, apps[1].WorkStatus, "hasn't been worked yet")
+	}
+}
+
+func TestFirstJobsAppointmentsGone (t *testing.T) {
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/jobs/job_1/appointments/appt_1":
+			assert.Equal (t, http.MethodDelete, r.Method)
+			w.WriteHeader (http.StatusNoContent)
+		case "/jobs/job_1/appointments":
+			w.Write ([]byte(`{"appointments":[{"id":"appt_2","start_time":"2025-04-28T14:00:00Z","end_time":"2025-04-28T22:00:00Z"}]}`))
+		case "/jobs/job_2/appointments/appt_1", "/jobs/job_2/appointments":
+			w.WriteHeader (http.StatusNotFound)
+		case "/jobs/job_3/appointments/appt_1":
+			w.WriteHeader (http.StatusBadRequest)
+			w.Write ([]byte(`{"error":{"message":"Archived job"}}`))
+		default:
+			w.WriteHeader (http.StatusForbidden)
+		}
+	}, WithRetryPolicy (RetryPolicy{}))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	assert.Equal (t, nil, hc.DeleteAppointment (ctx, "token", "job_1", "appt_1"))
+	assert.Equal (t, nil, hc.DeleteAppointment (ctx, "token", "job_2", "appt_1"), "already gone")
+	assert.Equal (t, nil, hc.DeleteAppointment (ctx, "token", "job_3", "appt_1"), "archived")
+	assert.NotEqual (t, nil, hc.DeleteAppointment (ctx, "token", "job_4", "appt_1"), "not allowed")
+
+	apps, err := hc.ListJobAppointments (ctx, "token", "job_1")
+	if err != nil { t.Fatal (err) }
+	if assert.Equal (t, 1, len(apps)) {
+		assert.Equal (t, "appt_2", apps[0].Id)
+	}
+
+	apps, err = hc.ListJobAppointments (ctx, "token", "job_2")
+	assert.Equal (t, nil, err)
+	assert.Equal (t, 0, len(apps))
+}
+
+func TestFirstJobsDispatch (t *testing.T) {
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		assert.Equal (t, http.MethodPut, r.Method)
+		body, _ := ioutil.ReadAll (r.Body)
+
+		switch r.URL.Path {
+		case "/jobs/job_1/dispatch", "/estimates/est_1/options/opt_1/dispatch":
+			assert.JSONEq (t, `{"dispatched_employees":[{"employee_id":"pro_1"},{"employee_id":"pro_2"}]}`, string(body))
+		default:
+			t.Errorf ("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	assert.Equal (t, nil, hc.DispatchJob (ctx, "token", "job_1", []string{ "pro_1", "pro_2" }))
+	assert.Equal (t, nil, hc.DispatchEstimateOption (ctx, "token", "est_1", "opt_1", []string{ "pro_1", "pro_2" }))
+}
+
+func TestFirstJobsUpdate (t *testing.T) {
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		assert.Equal (t, "/jobs/job_1", r.URL.Path)
+
+		switch r.Method {
+		case http.MethodPatch:
+			body, _ := ioutil.ReadAll (r.Body)
+			assert.JSONEq (t, `{"description":"","tags":["routed"]}`, string(body), "only what we set")
+		case http.MethodGet:
+			w.Write ([]byte(`{"id":"job_1","tags":["routed"]}`))
+		}
+	})
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	desc := ""
+	tags := []string{ "routed" }
+
+	job, err := hc.UpdateJob (ctx, "token", "job_1", JobUpdate { Description: &desc, Tags: &tags })
+	if err != nil { t.Fatal (err) }
+
+	assert.Equal (t, []string{ "routed" }, job.Tags)
+}
+
+func TestFirstJobsTags (t *testing.T) {
+	var patches int32
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPatch:
+			atomic.AddInt32 (&patches, 1)
+			body, _ := ioutil.ReadAll (r.Body)
+			assert.JSONEq (t, `{"tags":["vip","routed"]}`, string(body), "kept the others, dropped old")
+			w.WriteHeader (http.StatusOK)
+		case http.MethodGet:
+			switch r.URL.Path {
+			case "/jobs/job_1", "/jobs/job_2":
+				w.Write ([]byte(`{"id":"job_1","tags":["vip","old"]}`))
+			case "/jobs/job_3":
+				w.Write ([]byte(`{"id":"job_3","tags":["vip","routed"]}`))
+			default:
+				w.WriteHeader (http.StatusNotFound)
+			}
+		}
+	}, WithRetryPolicy (RetryPolicy{}))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	results := hc.BatchUpdateJobTags (ctx, "token", []string{ "job_1", "job_2", "job_3", "job_4", "job_1" }, []string{ "routed" }, []string{ "old" }, 2)
+
+	assert.Equal (t, 4, len(results))
+	assert.Equal (t, nil, results["job_1"])
+	assert.Equal (t, nil, results["job_2"])
+	assert.Equal (t, nil, results["job_3"])
+	assert.Equal (t, true, errors.Is (results["job_4"], ErrNotFound))
+	assert.Equal (t, int32(2), atomic.LoadInt32 (&patches), "job_3 already had the right tags, and job_1 is only done once")
+
+	tags, changed := changeTags ([]string{ "a", "b" }, []string{ "b" }, nil)
+	assert.Equal (t, []string{ "a", "b" }, tags)
+	assert.Equal (t, false, changed)
+
+	_, changed = changeTags ([]string{ "a", "b" }, nil, []string{ "c" })
+	assert.Equal (t, false, changed, "wasn't there to remove")
+}
+
+func TestFirstJobsNotes (t *testing.T) {
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /jobs/job_1":
+			w.Write ([]byte(`{"id":"job_1","notes":[{"id":"nte_1","content":"gate code 1234","created_at":"2025-04-27T14:00:00Z"}]}`))
+		case "POST /jobs/job_1/notes":
+			body, _ := ioutil.ReadAll (r.Body)
+			assert.JSONEq (t, `{"content":"running late"}`, string(body))
+			w.Write ([]byte(`{"id":"nte_2","content":"running late","created_at":"2025-04-28T14:00:00Z"}`))
+		case "DELETE /jobs/job_1/notes/nte_1":
+			w.WriteHeader (http.StatusNoContent)
+		default:
+			w.WriteHeader (http.StatusNotFound)
+		}
+	}, WithRetryPolicy (RetryPolicy{}))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	notes, err := hc.ListJobNotes (ctx, "token", "job_1")
+	if err != nil { t.Fatal (err) }
+	if assert.Equal (t, 1, len(notes)) {
+		assert.Equal (t, "gate code 1234", notes[0].Content)
+	}
+
+	note, err := hc.CreateJobNote (ctx, "token", "job_1", "running late")
+	if err != nil { t.Fatal (err) }
+	assert.Equal (t, "nte_2", note.Id)
+
+	assert.Equal (t, nil, hc.DeleteJobNote (ctx, "token", "job_1", "nte_1"))
+	assert.Equal (t, nil, hc.DeleteJobNote (ctx, "token", "job_1", "nte_3"), "already gone")
+}
+
+func TestFirstJobsLineItems (t *testing.T) {
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll (r.Body)
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /jobs/job_1/line_items":
+			assert.JSONEq (t, `{"name":"Tune up","description":"","unit_price":12500,"quantity":1,"unit_cost":0,"kind":"labor",
+				"taxable":true,"service_item_id":"pbs_1","service_item_type":"service"}`, string(body), "no id")
+			w.Write ([]byte(`{"id":"li_1","name":"Tune up","unit_price":12500,"taxable":true,"service_item_id":"pbs_1"}`))
+		case "PUT /jobs/job_1/line_items/li_1":
+			w.Write (body)
+		case "DELETE /jobs/job_1/line_items/li_1":
+			w.WriteHeader (http.StatusNoContent)
+		case "PUT /jobs/job_1/line_items/bulk_update":
+			assert.JSONEq (t, `{"line_items":[],"append_line_items":false}`, string(body))
+		case "GET /jobs/job_1/line_items":
+			w.Write ([]byte(`{"data":[]}`))
+		default:
+			w.WriteHeader (http.StatusNotFound)
+		}
+	}, WithRetryPolicy (RetryPolicy{}))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	taxable := true
+	item, err := hc.CreateLineItem (ctx, "token", "job_1", LineItem { Id: "ignored", Name: "Tune up", UnitPrice: 12500, Quantity: "1", 
+								Kind: "labor", Taxable: &taxable, ServiceItemId: "pbs_1", ServiceItemType: "service" })
+	if err != nil { t.Fatal (err) }
+	assert.Equal (t, "li_1", item.Id)
+	if assert.NotEqual (t, (*bool)(nil), item.Taxable) {
+		assert.Equal (t, true, *item.Taxable)
+	}
+
+	// when it's not set, we leave it to hcp
+	data, _ := json.Marshal (LineItem { Name: "Tune up" })
+	assert.NotContains (t, string(data), "taxable")
+
+	item.UnitPrice = 15000
+	item, err = hc.UpdateLineItem (ctx, "token", "job_1", *item)
+	if err != nil { t.Fatal (err) }
+	assert.Equal (t, Money(15000), item.UnitPrice)
+
+	_, err = hc.UpdateLineItem (ctx, "token", "job_1", LineItem{})
+	assert.NotEqual (t, nil, err, "needs an id")
+
+	assert.Equal (t, nil, hc.DeleteLineItem (ctx, "token", "job_1", "li_1"))
+	assert.Equal (t, nil, hc.DeleteLineItem (ctx, "token", "job_1", "li_2"), "already gone")
+
+	items, err := hc.ReplaceLineItems (ctx, "token", "job_1", nil)
+	if err != nil { t.Fatal (err) }
+	assert.Equal (t, 0, len(items))
+}
+
+func TestFirstJobsTransitions (t *testing.T) {
+	var posts []string
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts = append (posts, r.URL.Path)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/jobs/job_1":
+			if !expands (r, "appointments") {
+				w.Write ([]byte(`{"id":"job_1","work_status":"complete unrated"}`)) // hcp leaves them out
+				return
+			}
+			w.Write ([]byte(multiDayJobJson))
+		case "/jobs/job_2":
+			w.Write ([]byte(`{"id":"job_2","work_status":"pro canceled"}`))
+		}
+	})
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	// the job was completed, but that was only for the first day
+	_, err := hc.TransitionJob (ctx, "token", "job_1", JobTransition_start)
+	assert.Equal (t, ErrInvalidTransition, errors.Cause (err))
+
+	_, err = hc.TransitionAppointment (ctx, "token", "job_1", "appt_1", JobTransition_complete)
+	assert.Equal (t, ErrInvalidTransition, errors.Cause (err), "already done")
+
+	_, err = hc.TransitionAppointment (ctx, "token", "job_1", "appt_2", JobTransition_onMyWay)
+	assert.Equal (t, nil, err)
+
+	_, err = hc.TransitionAppointment (ctx, "token", "job_1", "appt_9", JobTransition_onMyWay)
+	assert.Equal (t, true, errors.Is (err, ErrNotFound))
+
+	_, err = hc.TransitionJob (ctx, "token", "job_2", JobTransition_complete)
+	assert.Equal (t, true, errors.Is (err, ErrInvalidTransition), "can't complete a canceled job")
+
+	assert.Equal (t, []string{ "/jobs/job_1/appointments/appt_2/on_my_way" }, posts)
+
+	assert.Equal (t, true, WorkStatus_inProgress.Allows (JobTransition_complete))
+	assert.Equal (t, false, WorkStatus_inProgress.Allows (JobTransition_start))
+	assert.Equal (t, false, WorkStatus_userCanceled.Allows (JobTransition_proCancel))
+}
+
+func TestFirstJobsAttachments (t *testing.T) {
+	var hcUrl string
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /jobs/job_1":
+			if !expands (r, "attachments") {
+				w.Write ([]byte(`{"id":"job_1"}`)) // hcp leaves them out
+				return
+			}
+			w.Write ([]byte(`{"id":"job_1","attachments":[{"id":"att_1","file_name":"before.jpg","file_type":"image/jpeg","url":"` + hcUrl + `/files/att_1"}]}`))
+		case "POST /jobs/job_1/attachments":
+			file, header, err := r.FormFile ("file")
+			if err != nil {
+				t.Error (err)
+				return
+			}
+			defer file.Close()
+
+			data, _ := ioutil.ReadAll (file)
+			assert.Equal (t, "after.jpg", header.Filename)
+			assert.Equal (t, "image/jpeg", header.Header.Get ("Content-Type"))
+			assert.Equal (t, "jpeg bytes", string(data))
+			assert.Equal (t, int64(-1), r.ContentLength, "should be streamed")
+
+			w.Write ([]byte(`{"id":"att_2","file_name":"after.jpg","file_type":"image/jpeg"}`))
+		case "GET /files/att_1":
+			assert.Equal (t, "Bearer token", r.Header.Get ("Authorization"))
+			w.Write ([]byte(`before bytes`))
+		default:
+			w.WriteHeader (http.StatusNotFound)
+		}
+	}, WithRetryPolicy (RetryPolicy{}))
+	hcUrl = hc.baseURL
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	attachments, err := hc.ListJobAttachments (ctx, "token", "job_1")
+	if err != nil { t.Fatal (err) }
+	if !assert.Equal (t, 1, len(attachments)) { return }
+
+	body, err := hc.DownloadAttachment (ctx, "token", attachments[0])
+	if err != nil { t.Fatal (err) }
+	data, _ := ioutil.ReadAll (body)
+	body.Close()
+	assert.Equal (t, "before bytes", string(data))
+
+	_, err = hc.DownloadAttachment (ctx, "token", Attachment { Id: "att_9", Url: "files/att_9" })
+	assert.Equal (t, true, errors.Is (err, ErrNotFound))
+
+	attachment, err := hc.UploadJobAttachment (ctx, "token", "job_1", "after.jpg", "image/jpeg", strings.NewReader ("jpeg bytes"))
+	if err != nil { t.Fatal (err) }
+	assert.Equal (t, "att_2", attachment.Id)
+}
+
 func TestThirdJobs (t *testing.T) {
 	hc, cfg := newHouseCall (t)
 
@@ -15,6 +15,8 @@ import (
 	"time"
 	"strings"
 	"strconv"
+	"sort"
+	"sync"
 	"encoding/json"
 )
 
@@ -35,6 +37,63 @@ const (
 	
 )
 
+// the ways our pros can move a job, or one of its appointments, through its work statuses
+type JobTransition string
+
+const (
+	JobTransition_onMyWay 			JobTransition = "on_my_way"
+	JobTransition_start 			JobTransition = "start"
+	JobTransition_complete 			JobTransition = "complete"
+	JobTransition_proCancel 		JobTransition = "cancel"
+)
+
+// the work statuses each transition can be made from
+// on my way doesn't change the status, so it can be sent more than once
+var jobTransitionsFrom = map[JobTransition][]WorkStatus {
+	JobTransition_onMyWay: 		{ WorkStatus_scheduled },
+	JobTransition_start: 		{ WorkStatus_scheduled },
+	JobTransition_complete: 	{ WorkStatus_scheduled, WorkStatus_inProgress },
+	JobTransition_proCancel: 	{ WorkStatus_needsScheduling, WorkStatus_scheduled, WorkStatus_inProgress },
+}
+
+// returns that a job in this status can make the transition
+func (this WorkStatus) Allows (to JobTransition) bool {
+	for _, from := range jobTransitionsFrom[to] {
+		if this == from { return true }
+	}
+	return false
+}
+
+// the work statuses HCP lets us filter lists by, these aren't the same as the WorkStatus values
+type WorkStatusFilter string
+
+const (
+	WorkStatusFilter_unscheduled 	WorkStatusFilter = "unscheduled"
+	WorkStatusFilter_scheduled 		WorkStatusFilter = "scheduled"
+	WorkStatusFilter_inProgress 	WorkStatusFilter = "in_progress"
+	WorkStatusFilter_completed 		WorkStatusFilter = "completed"
+	WorkStatusFilter_canceled 		WorkStatusFilter = "canceled"
+)
+
+type InvoiceStatus string
+
+const (
+	InvoiceStatus_open 				InvoiceStatus = "open"
+	InvoiceStatus_pendingPayment 	InvoiceStatus = "pending_payment"
+	InvoiceStatus_paid 				InvoiceStatus = "paid"
+	InvoiceStatus_voided 			InvoiceStatus = "voided"
+	InvoiceStatus_canceled 			InvoiceStatus = "canceled"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatus_pending 			PaymentStatus = "pending"
+	PaymentStatus_succeeded 		PaymentStatus = "succeeded"
+	PaymentStatus_failed 			PaymentStatus = "failed"
+	PaymentStatus_refunded 			PaymentStatus = "refunded"
+)
+
 const apiURL = "https://api.housecallpro.com"
 
 //----- ERRORS ---------------------------------------------------------------------------------------------------------//
@@ -43,19 +102,130 @@ var (
 	ErrInvalidCode 		= errors.New("OAuth code not valid")
 	ErrAuthExpired		= errors.New("OAuth expired")
 	ErrTooManyRecords	= errors.New("Too many records returned")
+	ErrTokenNotFound	= errors.New("No tokens saved for company")
+	ErrInvalidState		= errors.New("OAuth state not valid")
+	ErrInvalidTransition	= errors.New("Job can't make that transition from its current work status")
+
+	// returned from requests to HCP, check them with errors.Is
+	ErrNotFound			= errors.New("Not found")
+	ErrGone				= errors.New("Gone")
+	ErrArchived			error = &archivedError{} // errors.Is matches both this and ErrGone, errors.Cause stops at this
+	ErrRateLimited		= errors.New("Rate limited")
+	ErrValidation		= errors.New("Validation failed")
+	ErrServer			= errors.New("HouseCall server error")
+	ErrUnexpected		= errors.New("Unexpected HouseCall error")
 )
 
   //-----------------------------------------------------------------------------------------------------------------------//
  //----- STRUCTS ---------------------------------------------------------------------------------------------------------//
 //-----------------------------------------------------------------------------------------------------------------------//
 
+// happens when someone deletes the job, which is also a gone job
+// it's its own type so that errors.Cause returns ErrArchived, but it unwraps to ErrGone for errors.Is
+type archivedError struct {}
+
+func (this *archivedError) Error () string { return "Archived job" }
+func (this *archivedError) Unwrap () error { return ErrGone }
+
 //----- ERRORS ---------------------------------------------------------------------------------------------------------//
 type Error struct {
 	ErrMsg, Description string 
 	StatusCode int 
+	retryAfter time.Duration // set from the Retry-After header, if there was one
+	additional string // context about the request, included in the message
+	fields map[string][]string // field name to the messages about it, from validation errors
+}
+
+// returned from Error.Err when HCP rejects the request because of the data in it, 400 and 422
+// use errors.As to get this and see which fields were the problem
+type ValidationError struct {
+	Fields map[string][]string // field name to the messages about it, can be empty if HCP didn't say
+	err *Error
+}
+
+func (this *ValidationError) Error () string {
+	if len(this.Fields) == 0 { return this.err.Error() } // nothing extra to add
+
+	names := make([]string, 0, len(this.Fields))
+	for name := range this.Fields {
+		names = append (names, name)
+	}
+	sort.Strings (names) // so the message is always the same
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append (parts, fmt.Sprintf ("%s %s", name, strings.Join (this.Fields[name], ", ")))
+	}
+	return fmt.Sprintf ("%s : %s", this.err.Error(), strings.Join (parts, "; "))
+}
+
+// leads to the *Error, and then ErrValidation
+func (this *ValidationError) Unwrap () error {
+	return this.err
+}
+
+// allows errors.Cause to keep returning ErrValidation
+func (this *ValidationError) Cause () error {
+	return this.err.Cause()
+}
+
+// returns the first message for this field, or an empty string if the field was fine
+func (this *ValidationError) Field (name string) string {
+	if len(this.Fields[name]) == 0 { return "" }
+	return this.Fields[name][0]
+}
+
+// adds the field errors from one of the formats HCP uses
+// {"first_name":["can't be blank"]} or {"first_name":"can't be blank"} or [{"field":"first_name","message":"can't be blank"}]
+func addFieldErrors (fields map[string][]string, raw json.RawMessage) {
+	if len(raw) == 0 { return }
+
+	var byName map[string]json.RawMessage
+	if json.Unmarshal (raw, &byName) == nil {
+		for name, val := range byName {
+			var msgs []string
+			var msg string
+			if json.Unmarshal (val, &msgs) == nil {
+				fields[name] = append (fields[name], msgs...)
+			} else if json.Unmarshal (val, &msg) == nil {
+				fields[name] = append (fields[name], msg)
+			}
+		}
+		return
+	}
+
+	var list []struct {
+		Field, Attribute, Message string
+	}
+	if json.Unmarshal (raw, &list) == nil {
+		for _, l := range list {
+			name := l.Field
+			if len(name) == 0 { name = l.Attribute }
+			fields[name] = append (fields[name], l.Message)
+		}
+	}
+}
+
+// looks for field level errors, either at the top level or inside the error object
+func parseFieldErrors (b []byte) map[string][]string {
+	var top map[string]json.RawMessage
+	if json.Unmarshal (b, &top) != nil { return nil }
+
+	fields := make(map[string][]string)
+	addFieldErrors (fields, top["errors"])
+
+	var inner map[string]json.RawMessage
+	if json.Unmarshal (top["error"], &inner) == nil { // error can also just be a string
+		addFieldErrors (fields, inner["errors"])
+		addFieldErrors (fields, inner["details"])
+	}
+
+	if len(fields) == 0 { return nil }
+	return fields
 }
 
 func (this *Error) UnmarshalJSON (b []byte) error {
+	this.fields = parseFieldErrors (b)
 
 	// try this way
 	var one struct {
@@ -85,13 +255,16 @@ func (this *Error) UnmarshalJSON (b []byte) error {
 			this.ErrMsg = two.Error 
 			this.Description = two.Description
 			this.StatusCode = two.StatusCode
-		} else {
+		} else if len(this.fields) == 0 { // if we have fields, that's enough
 			this.ErrMsg = err.Error()
 			this.Description = string(b)
 		}
 	}
 
-	if len(this.ErrMsg) == 0 {
+	if len(this.ErrMsg) == 0 && len(this.fields) > 0 {
+		this.ErrMsg = "Validation failed" // all we got were the fields
+		
+	} else if len(this.ErrMsg) == 0 {
 		// this didn't work
 		this.ErrMsg = "Unkown struct type"
 		this.Description = string(b)
@@ -99,20 +272,59 @@ func (this *Error) UnmarshalJSON (b []byte) error {
 	return nil 
 }
 
-func (this *Error) Err (additional string) error {
-	if this == nil { return nil } // no error
-	
-	if this.ErrMsg == "invalid_grant" { // this is for granting access based on the passed code
-		return errors.Wrap (ErrInvalidCode, this.Description)
-	}
+// maps this error to one of our sentinel errors, so callers can use errors.Is
+func (this *Error) sentinel () error {
+	if this.ErrMsg == "invalid_grant" { return ErrInvalidCode } // this is for granting access based on the passed code
+
+	// happens when someone deletes the job and not just the appointment for the job
+	// comes back as either a 400 : Archived job or "Can't schedule an archived job"
+	if strings.Contains (strings.ToLower (this.ErrMsg), "archived job") { return ErrArchived }
 
 	switch this.StatusCode {
 	case http.StatusUnauthorized:
-		return errors.Wrap (ErrAuthExpired, this.Description) // invalid for another reason, most likely the oauth has been revoked
+		return ErrAuthExpired // invalid for another reason, most likely the oauth has been revoked
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusGone:
+		return ErrGone
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrValidation
+	}
+
+	if this.StatusCode > 499 { return ErrServer }
+	return ErrUnexpected // just a default
+}
+
+// so the Error can be returned as an error itself
+func (this *Error) Error () string {
+	return fmt.Sprintf ("HouseCall Error : %d : %s : %s : %s", this.StatusCode, this.ErrMsg, this.additional, this.Description)
+}
+
+// allows errors.Is to match against our sentinel errors
+func (this *Error) Unwrap () error {
+	return this.sentinel()
+}
+
+// allows errors.Cause to keep returning our sentinel errors
+func (this *Error) Cause () error {
+	return this.sentinel()
+}
+
+// returns this as an error, additional is included in the message for some context about the request
+// use errors.Is to check for the sentinel errors, or errors.As to get the *Error with the status code
+func (this *Error) Err (additional string) error {
+	if this == nil { return nil } // no error
 	
+	ret := *this // copy this so the additional info stays with the returned error
+	ret.additional = additional
+
+	if ret.sentinel() == ErrValidation {
+		return errors.WithStack (&ValidationError { Fields: ret.fields, err: &ret })
 	}
-	// just a default
-	return errors.Errorf ("HouseCall Error : %d : %s : %s : %s", this.StatusCode, this.ErrMsg, additional, this.Description)
+	
+	return errors.WithStack (&ret)
 }
 
 //----- OAUTH ---------------------------------------------------------------------------------------------------------//
@@ -434,6 +646,18 @@ type Appointment struct {
 	AssignedEmployees []string `json:"dispatched_employees_ids"`
 }
 
+// a single appointment along with what we need from its job to dispatch it
+// WorkStatus is for this appointment, and will be scheduled when the job's status is from another one
+// these aren't full copies, views from the same job share the slices in Customer, Crew and AssignedEmployees
+type AppointmentView struct {
+	Appointment
+	JobId string
+	Customer Customer
+	Address Address
+	Crew []Employee // the job's employees assigned to this appointment
+	WorkStatus WorkStatus
+}
+
 type Job struct {
 	Id string `json:"id"`
 	CustomerId string `json:"customer_id"`
@@ -442,8 +666,8 @@ type Job struct {
 	Note string `json:"note"`
 	WorkStatus WorkStatus `json:"work_status"`
 	Invoice string `json:"invoice_number"`
-	Balance int64 `json:"outstanding_balance"`
-	Total int64 `json:"total_amount"`
+	Balance Money `json:"outstanding_balance"`
+	Total Money `json:"total_amount"`
 	Tags []string `json:"tags"`
 	Description string `json:"description"`
 	AssignedEmployees []Employee `json:"assigned_employees"`
@@ -459,6 +683,24 @@ type Job struct {
 		Completed time.Time `json:"completed_at"`
 	} `json:"work_timestamps"`
 	LeadSource string `json:"lead_source,omitempty"`
+	Notes []JobNote `json:"notes"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+// one of the notes on a job, Job.Note is just the latest one
+type JobNote struct {
+	Id string `json:"id"`
+	Content string `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// a file on a job, like the before and after photos
+// Url is where to download it from, use DownloadAttachment for that
+type Attachment struct {
+	Id string `json:"id"`
+	FileName string `json:"file_name"`
+	FileType string `json:"file_type"`
+	Url string `json:"url"`
 }
 
 // returns that the job is in a state where the job is still expected to be completed in the future
@@ -479,7 +721,91 @@ func (this *Job) IsActive () bool {
 	return false // not an active job
 }
 
-// takes a single job and expands it into multiple for each appointment, if needed
+// the job's status and timestamps are from whichever appointment was worked on last
+// returns false when they're from another appointment, meaning this one is still just scheduled
+func (this *Job) statusApplies (app Appointment) bool {
+	if this.IsPending() { return true } // nothing has happened yet, so it applies to all of them
+
+	// only thing we have to look for is when the on my way, or started, or completed timestamps are
+	threshold := app.Start.Add(time.Hour * -12)
+	return this.WorkTimestamps.Completed.After(threshold) ||
+		this.WorkTimestamps.Started.After(threshold) ||
+		this.WorkTimestamps.OnMyWay.After(threshold)
+}
+
+// returns just the employees on the job that are assigned to this appointment
+func (this *Job) appointmentCrew (app Appointment) []Employee {
+	targetCrews := make(map[string]struct{})
+	for _, id := range app.AssignedEmployees {
+		targetCrews[id] = struct{}{} // this exists
+	}
+
+	// now find the ones we want
+	ret := make([]Employee, 0, 1)
+	for _, emp := range this.AssignedEmployees {
+		if _, ok := targetCrews[emp.Id]; ok {
+			ret = append (ret, emp)
+		}
+	}
+	return ret
+}
+
+// returns the appointments for the job, older jobs without any only have the job's schedule
+func (this *Job) appointments () []Appointment {
+	if len(this.Schedule.Appointments) > 0 { return this.Schedule.Appointments }
+	if this.Schedule.Start.IsZero() { return nil } // not scheduled
+
+	app := Appointment {
+		Start: this.Schedule.Start,
+		End: this.Schedule.End,
+		Window: this.Schedule.Window,
+	}
+	for _, emp := range this.AssignedEmployees {
+		app.AssignedEmployees = append (app.AssignedEmployees, emp.Id)
+	}
+	return []Appointment{ app }
+}
+
+// returns a copy of the job that doesn't share any slices with this one, so changing one never changes the other
+func (this *Job) clone () *Job {
+	ret := *this
+
+	ret.Customer.Tags = copyStrings (this.Customer.Tags)
+	if this.Customer.Addresses != nil { ret.Customer.Addresses = append(make([]Address, 0, len(this.Customer.Addresses)), this.Customer.Addresses...) }
+	ret.Tags = copyStrings (this.Tags)
+	if this.Notes != nil { ret.Notes = append(make([]JobNote, 0, len(this.Notes)), this.Notes...) }
+	if this.Attachments != nil { ret.Attachments = append(make([]Attachment, 0, len(this.Attachments)), this.Attachments...) }
+	ret.AssignedEmployees = copyEmployees (this.AssignedEmployees)
+
+	if this.Schedule.Appointments != nil {
+		ret.Schedule.Appointments = make([]Appointment, len(this.Schedule.Appointments))
+		for i, app := range this.Schedule.Appointments {
+			app.AssignedEmployees = copyStrings (app.AssignedEmployees)
+			ret.Schedule.Appointments[i] = app
+		}
+	}
+
+	return &ret
+}
+
+func copyStrings (list []string) []string {
+	if list == nil { return nil }
+	return append(make([]string, 0, len(list)), list...)
+}
+
+func copyEmployees (list []Employee) []Employee {
+	if list == nil { return nil }
+
+	ret := make([]Employee, len(list))
+	for i, emp := range list {
+		emp.Tags = copyStrings (emp.Tags)
+		ret[i] = emp
+	}
+	return ret
+}
+
+// takes a single job and expands it into multiple for each appointment that overlaps start and end, if needed
+// each new job is a full copy, so changing one doesn't change the original or the jobs for the other appointments
 func expandJob (job *Job, start, end time.Time) (ret []*Job) {
 	// i think we want to include jobs with no assigned employee
 	// if len(job.AssignedEmployees) == 0 { return nil } // don't include anything
@@ -487,16 +813,16 @@ func expandJob (job *Job, start, end time.Time) (ret []*Job) {
 	if len(job.Schedule.Appointments) < 2 { return append(ret, job) } // we only have the 1 option, so we're good
 
 	// ok, we need to figure out which appointment matters for this
+	// any appointment that overlaps our range counts, even if it started before or ends after
 	for _, app := range job.Schedule.Appointments {
-		if app.Start.Before(start) { continue }
-		if app.End.After(end) { continue }
+		if !app.Start.Before(end) { continue }
+		if !app.End.After(start) { continue }
 
 		// this matches our target
 		// make a copy of our current job
-		jobStr, _ := json.Marshal(job)
-		newJob := &Job{}
-		json.Unmarshal(jobStr, newJob)
+		newJob := job.clone()
 
+		app.AssignedEmployees = copyStrings (app.AssignedEmployees)
 		newJob.Schedule.Appointments = append(make([]Appointment, 0, 1), app) // just give this new job the one appointment
 		
 		// this appointment needs to update the scheduled start, end and window
@@ -505,39 +831,16 @@ func expandJob (job *Job, start, end time.Time) (ret []*Job) {
 		newJob.Schedule.Window = app.Window
 
 		// we want to make sure the work status makes sense for this appointment
-		// only thing we have to look for is when the on my way, or started, or completed timestamps are
-		if newJob.IsPending() == false {
-			// let's see if it's supposed to be pending
-			threshold := newJob.Schedule.Start.Add(time.Hour * -12)
-			if newJob.WorkTimestamps.Completed.After(threshold) ||
-				newJob.WorkTimestamps.Started.After(threshold) ||
-				newJob.WorkTimestamps.OnMyWay.After(threshold) {
-					// this status is correct, so keep it
-			} else {
-				// this was for another appointment, meaning our appointment is scheduled again
-				newJob.WorkStatus = WorkStatus_scheduled
-				newJob.WorkTimestamps.Completed = time.Time{} // clear these
-				newJob.WorkTimestamps.Started = time.Time{}
-				newJob.WorkTimestamps.OnMyWay = time.Time{}
-			}
+		if !job.statusApplies (app) {
+			// this was for another appointment, meaning our appointment is scheduled again
+			newJob.WorkStatus = WorkStatus_scheduled
+			newJob.WorkTimestamps.Completed = time.Time{} // clear these
+			newJob.WorkTimestamps.Started = time.Time{}
+			newJob.WorkTimestamps.OnMyWay = time.Time{}
 		}
 		
 		// now remove the crew that we shouldn't have
-		// we only want the crew members associated with this appointment
-		targetCrews := make(map[string]struct{})
-		for _, id := range app.AssignedEmployees {
-			targetCrews[id] = struct{}{} // this exists
-		}
-
-		// now find the ones we want
-		finalEmployees := make([]Employee, 0, 1)
-		for _, emp := range newJob.AssignedEmployees {
-			if _, ok := targetCrews[emp.Id]; ok {
-				finalEmployees = append (finalEmployees, emp)
-			}
-		}
-
-		newJob.AssignedEmployees = finalEmployees // just set the new slice
+		newJob.AssignedEmployees = copyEmployees (job.appointmentCrew (app))
 
 		// add this to our return
 		ret = append(ret, newJob)
@@ -546,6 +849,38 @@ func expandJob (job *Job, start, end time.Time) (ret []*Job) {
 	return // we're good
 }
 
+// returns a view of each appointment for the job that overlaps start and end
+func jobAppointments (job *Job, start, end time.Time) (ret []AppointmentView) {
+	for _, app := range job.appointments() {
+		if !app.Start.Before(end) { continue }
+		if !app.End.After(start) { continue }
+
+		view := AppointmentView {
+			Appointment: app,
+			JobId: job.Id,
+			Customer: job.Customer,
+			Address: job.Address,
+			Crew: job.appointmentCrew (app),
+			WorkStatus: job.WorkStatus,
+		}
+		if !job.statusApplies (app) { view.WorkStatus = WorkStatus_scheduled } // this one hasn't been worked yet
+
+		ret = append(ret, view)
+	}
+
+	return // we're good
+}
+
+// returns that the appointment has one of these employees assigned to it
+func (this *AppointmentView) hasEmployee (employeeIds []string) bool {
+	for _, want := range employeeIds {
+		for _, id := range this.AssignedEmployees {
+			if id == want { return true }
+		}
+	}
+	return false
+}
+
 type DispatchedEmployee struct {
 	Id string `json:"employee_id"`
 }
@@ -559,10 +894,73 @@ type JobSchedule struct {
 	DispatchedEmployees []DispatchedEmployee `json:"dispatched_employees"`
 }
 
+// just the employees for a job or estimate option, used when we're not changing the schedule
 type JobDispatch struct {
 	DispatchedEmployees []DispatchedEmployee `json:"dispatched_employees"`
 }
 
+// a range of time used for filtering lists, leave either side zero to not limit that side
+type TimeRange struct {
+	Min, Max time.Time
+}
+
+// sets the _min and _max params for this range, if they're set
+func (this TimeRange) setParams (params url.Values, name string) {
+	if !this.Min.IsZero() { params.Set(name + "_min", this.Min.Format(time.RFC3339)) }
+	if !this.Max.IsZero() { params.Set(name + "_max", this.Max.Format(time.RFC3339)) }
+}
+
+// all the filters HCP has for listing jobs, anything left empty isn't filtered on
+type ListJobsOptions struct {
+	PageOptions
+	EmployeeIds []string
+	CustomerId string
+	WorkStatuses []WorkStatusFilter
+	ScheduledStart, ScheduledEnd TimeRange
+	Created, Updated TimeRange
+	Tags []string
+	SortBy string // HCP defaults to created_at
+	SortDirection string // asc or desc, defaults to desc
+	Expand []string // extra objects to include with each job, like "appointments"
+}
+
+// converts our options into the url params for the request
+func (this ListJobsOptions) params () url.Values {
+	params := url.Values{}
+
+	for _, id := range this.EmployeeIds {
+		params.Add("employee_ids[]", id)
+	}
+	if len(this.CustomerId) > 0 {
+		params.Set("customer_id", this.CustomerId)
+	}
+	for _, status := range this.WorkStatuses {
+		params.Add("work_status[]", string(status))
+	}
+
+	this.ScheduledStart.setParams (params, "scheduled_start")
+	this.ScheduledEnd.setParams (params, "scheduled_end")
+	this.Created.setParams (params, "created_at")
+	this.Updated.setParams (params, "updated_at")
+
+	for _, tag := range this.Tags {
+		params.Add("tags[]", tag)
+	}
+	if len(this.SortBy) > 0 {
+		params.Set("sort_by", this.SortBy)
+	}
+	if len(this.SortDirection) > 0 {
+		params.Set("sort_direction", this.SortDirection)
+	} else {
+		params.Set("sort_direction", "desc")
+	}
+	for _, expand := range this.Expand {
+		params.Add("expand[]", expand)
+	}
+
+	return params
+}
+
 type jobListResponse struct {
 	Jobs []*Job `json:"jobs"`
 	TotalItems int `json:"total_items"`
@@ -570,12 +968,16 @@ type jobListResponse struct {
 }
 
 type LineItem struct {
+	Id string `json:"id,omitempty"` // set by HCP, leave empty when creating
 	Name string `json:"name"`
 	Description string `json:"description"`
-	UnitPrice int `json:"unit_price"`
+	UnitPrice Money `json:"unit_price"`
 	Quantity json.Number `json:"quantity"`
-	UnitCost int `json:"unit_cost"`
+	UnitCost Money `json:"unit_cost"`
 	Kind string `json:"kind"`
+	Taxable *bool `json:"taxable,omitempty"` // nil leaves it to HCP's default
+	ServiceItemId string `json:"service_item_id,omitempty"` // the service or material from the price book this came from
+	ServiceItemType string `json:"service_item_type,omitempty"` // "service" or "material"
 }
 
 type createJob struct {
@@ -593,6 +995,16 @@ type createJob struct {
 	Notes string `json:"notes,omitempty"`
 }
 
+// the fields to change with UpdateJob, leave a field nil to not change it
+// setting a field to its empty value clears it in HCP
+// notes are a list on the job, so use CreateJobNote and DeleteJobNote for those
+type JobUpdate struct {
+	Description *string `json:"description,omitempty"`
+	LeadSource *string `json:"lead_source,omitempty"`
+	Tags *[]string `json:"tags,omitempty"` // replaces all the tags on the job
+	InvoiceNumber *string `json:"invoice_number,omitempty"`
+}
+
 type createAppointment struct {
 	Start time.Time `json:"start_time"`
 	End time.Time `json:"end_time"`
@@ -636,7 +1048,7 @@ type estimateOption struct {
 	Id string `json:"id"`
 	Name string `json:"name"`
 	OptionNumber string `json:"option_number"`
-	TotalAmount int64 `json:"total_amount"`
+	TotalAmount Money `json:"total_amount"`
 	ApprovalStatus string `json:"approval_status"`
 	Status WorkStatus `json:"status"`
 	MessageFromPro string `json:"message_from_pro"`
@@ -651,6 +1063,53 @@ func (this *estimateOption) IsPending () bool {
 	return false // this is in a state where the job has been cancelled or already started
 }
 
+// all the filters HCP has for listing estimates, anything left empty isn't filtered on
+type ListEstimatesOptions struct {
+	PageOptions
+	EmployeeIds []string
+	CustomerId string
+	WorkStatuses []WorkStatusFilter
+	ApprovalStatuses []string // approval status of the options, like "approved" or "declined"
+	ScheduledStart, ScheduledEnd TimeRange
+	Created, Updated TimeRange
+	SortBy string // HCP defaults to created_at
+	SortDirection string // asc or desc, defaults to desc
+}
+
+// converts our options into the url params for the request
+func (this ListEstimatesOptions) params () url.Values {
+	params := url.Values{}
+
+	for _, id := range this.EmployeeIds {
+		params.Add("employee_ids[]", id)
+	}
+	if len(this.CustomerId) > 0 {
+		params.Set("customer_id", this.CustomerId)
+	}
+	for _, status := range this.WorkStatuses {
+		params.Add("work_status[]", string(status))
+	}
+	for _, status := range this.ApprovalStatuses {
+		params.Add("approval_status[]", status)
+	}
+
+	this.ScheduledStart.setParams (params, "scheduled_start")
+	this.ScheduledEnd.setParams (params, "scheduled_end")
+	this.Created.setParams (params, "created_at")
+	this.Updated.setParams (params, "updated_at")
+
+	if len(this.SortBy) > 0 {
+		params.Set("sort_by", this.SortBy)
+	}
+	if len(this.SortDirection) > 0 {
+		params.Set("sort_direction", this.SortDirection)
+	} else {
+		params.Set("sort_direction", "desc")
+	}
+
+	return params
+}
+
 type estimateListResponse struct {
 	Estimates []Estimate `json:"estimates"`
 	TotalItems int `json:"total_items"`
@@ -679,6 +1138,120 @@ type createEstimate struct {
 	Options []CreateEstimateOption `json:"options"`
 }
 
+//----- INVOICES -------------------------------------------------------------------------------------------------------//
+
+// a payment towards an invoice
+type Payment struct {
+	Id string `json:"id"`
+	InvoiceId string `json:"invoice_id"`
+	Amount Money `json:"amount"`
+	Method string `json:"payment_method"` // like "credit card", "cash" or "check"
+	Status PaymentStatus `json:"status"`
+	PaidAt time.Time `json:"paid_at"`
+	Reference string `json:"reference"` // the check number or the processor's transaction id
+}
+
+// returns that the money from this payment was actually collected
+// older payments don't have a status, those went through
+func (this *Payment) IsCollected () bool {
+	switch this.Status {
+	case PaymentStatus_succeeded, "":
+		return true
+	}
+	return false
+}
+
+// what we expect the balance on a job to be compared to what HCP says it is
+type BalanceCheck struct {
+	Total Money // from the job
+	Collected Money // the payments that went through
+	Expected Money // Total - Collected
+	Reported Money // Job.Balance
+}
+
+// returns that HCP's balance isn't what the payments add up to
+func (this BalanceCheck) Mismatch () bool {
+	return this.Expected != this.Reported
+}
+
+// recomputes the outstanding balance for the job from its total and payments
+// use Mismatch on the result to see if it agrees with the balance HCP reported
+func (this *Job) ReconcileBalance (payments []Payment) BalanceCheck {
+	ret := BalanceCheck { Total: this.Total, Reported: this.Balance }
+
+	for _, pay := range payments {
+		if pay.IsCollected() { ret.Collected = ret.Collected.Add (pay.Amount) }
+	}
+
+	ret.Expected = ret.Total.Sub (ret.Collected)
+	return ret
+}
+
+type Invoice struct {
+	Id string `json:"id"`
+	InvoiceNumber string `json:"invoice_number"` // this is what's in Job.Invoice
+	JobId string `json:"job_id"`
+	Status InvoiceStatus `json:"status"`
+	Amount Money `json:"amount"` // the total
+	Subtotal Money `json:"subtotal"`
+	DueAmount Money `json:"due_amount"` // what's still owed
+	InvoiceDate time.Time `json:"invoice_date"`
+	DueAt time.Time `json:"due_at"`
+	PaidAt time.Time `json:"paid_at"`
+	SentAt time.Time `json:"sent_at"`
+	Items []LineItem `json:"items"`
+	Payments []Payment `json:"payments"`
+}
+
+// filters for requesting invoices, anything left empty isn't filtered on
+type ListInvoicesOptions struct {
+	PageOptions
+	CustomerId string
+	JobId string
+	Statuses []InvoiceStatus
+	InvoiceDate, DueDate TimeRange
+	Created, Updated TimeRange
+	SortBy string // HCP defaults to created_at
+	SortDirection string // asc or desc, defaults to desc
+}
+
+// converts our options into the url params for the request
+func (this ListInvoicesOptions) params () url.Values {
+	params := url.Values{}
+
+	if len(this.CustomerId) > 0 {
+		params.Set("customer_id", this.CustomerId)
+	}
+	if len(this.JobId) > 0 {
+		params.Set("job_id", this.JobId)
+	}
+	for _, status := range this.Statuses {
+		params.Add("status[]", string(status))
+	}
+
+	this.InvoiceDate.setParams (params, "invoice_date")
+	this.DueDate.setParams (params, "due_at")
+	this.Created.setParams (params, "created_at")
+	this.Updated.setParams (params, "updated_at")
+
+	if len(this.SortBy) > 0 {
+		params.Set("sort_by", this.SortBy)
+	}
+	if len(this.SortDirection) > 0 {
+		params.Set("sort_direction", this.SortDirection)
+	} else {
+		params.Set("sort_direction", "desc")
+	}
+
+	return params
+}
+
+type invoiceListResponse struct {
+	Invoices []Invoice `json:"invoices"`
+	TotalItems int `json:"total_items"`
+	TotalPages int `json:"total_pages"`
+}
+
 //----- EVENTS ---------------------------------------------------------------------------------------------------------//
 
 type recurrence struct {
@@ -863,8 +1436,6 @@ func (this Event) ExtractRecurrence () (events []Event, err error) {
 			if err != nil {	return nil, errors.WithStack(err) }
 		}
 	}
-
-	return // we're done!!!
 }
 
 type eventListResponse struct {
@@ -878,6 +1449,12 @@ type eventListResponse struct {
 
 type HouseCall struct {
 	clientId, clientSecret, callbackUrl string // for making api calls
+	client *http.Client // used for all requests, defaults to http.DefaultClient
+	baseURL, userAgent string // where we send requests and how we identify ourselves
+	retry RetryPolicy // how we handle requests that fail for temporary reasons
+	limiter *rateLimiter // nil unless we were asked to limit our requests
+	store TokenStore // where ForCompany gets its tokens
+	companies sync.Map // company id to the *TokenSource for it
 }
 
 // populates our oauth request with the data we have from this object
@@ -894,7 +1471,7 @@ func (this *HouseCall) seedOAuth () *oauthRequest {
  //----- FUNCTIONS -------------------------------------------------------------------------------------------------------//
 //-----------------------------------------------------------------------------------------------------------------------//
 
-func NewHouseCall (clientId, clientSecret, callbackUrl string) (*HouseCall, error) {
+func NewHouseCall (clientId, clientSecret, callbackUrl string, opts ...Option) (*HouseCall, error) {
 	// validate some inputs
 	if len(clientId) != 64 { return nil, errors.Errorf ("client ID appears invalid.  Expecting a 64 character hash") }
 	if len(clientSecret) != 64 { return nil, errors.Errorf ("client secret appears invalid.  Expecting a 64 character hash") }
@@ -904,9 +1481,19 @@ func NewHouseCall (clientId, clientSecret, callbackUrl string) (*HouseCall, erro
 	if u.Scheme == "" || u.Host == "" { return nil, errors.Errorf ("%s is not a valid url", callbackUrl) }
     
 	// looks good
-	return &HouseCall { 
+	hc := &HouseCall { 
 		clientId: clientId, 
 		clientSecret: clientSecret,
 		callbackUrl: callbackUrl,
-	}, nil
+		client: http.DefaultClient,
+		baseURL: apiURL,
+		retry: DefaultRetryPolicy,
+	}
+
+	// now apply any of the options passed in
+	for _, opt := range opts {
+		if err := opt (hc); err != nil { return nil, err }
+	}
+
+	return hc, nil
 }
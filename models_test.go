@@ -4,7 +4,7 @@ package housecall
 import (
 	
 	"github.com/stretchr/testify/assert"
-	//"github.com/pkg/errors"
+	"github.com/pkg/errors"
 
 	"testing"
 	"time"
@@ -19,11 +19,11 @@ type testConfig struct {
 func TestFirstModelsError1 (t *testing.T) {
 	var err *Error 
 
-	assert.Equal (t, nil, err.Err(), "nil for a nil object")
+	assert.Equal (t, nil, err.Err(""), "nil for a nil object")
 
 	// give it some memory
 	err = &Error{}
-	assert.NotEqual (t, nil, err.Err(), "Should return an error")
+	assert.NotEqual (t, nil, err.Err(""), "Should return an error")
 	
 }
 
@@ -44,6 +44,61 @@ func TestFirstModelsError2 (t *testing.T) {
 
 }
 
+func TestFirstModelsErrorSentinels (t *testing.T) {
+	assert.Equal (t, true, errors.Is ((&Error{ StatusCode: 404 }).Err("job_1"), ErrNotFound))
+	assert.Equal (t, true, errors.Is ((&Error{ StatusCode: 410 }).Err("job_1"), ErrGone))
+	assert.Equal (t, true, errors.Is ((&Error{ StatusCode: 429 }).Err(""), ErrRateLimited))
+	assert.Equal (t, true, errors.Is ((&Error{ StatusCode: 422 }).Err(""), ErrValidation))
+	assert.Equal (t, true, errors.Is ((&Error{ StatusCode: 503 }).Err(""), ErrServer))
+	assert.Equal (t, true, errors.Is ((&Error{ StatusCode: 401 }).Err(""), ErrAuthExpired))
+	assert.Equal (t, ErrAuthExpired, errors.Cause ((&Error{ StatusCode: 401 }).Err("")), "errors.Cause still works")
+
+	// archived jobs are also gone
+	err := (&Error{ StatusCode: 400, ErrMsg: "Archived job" }).Err("job_1")
+	assert.Equal (t, true, errors.Is (err, ErrArchived))
+	assert.Equal (t, true, errors.Is (err, ErrGone))
+	assert.Equal (t, false, errors.Is (err, ErrValidation))
+	assert.Equal (t, ErrArchived, errors.Cause (err), "errors.Cause can tell it apart from gone")
+	assert.Equal (t, ErrGone, errors.Cause ((&Error{ StatusCode: 410 }).Err("job_1")))
+
+	// and we can still get to the status code
+	var errObj *Error
+	assert.Equal (t, true, errors.As (err, &errObj))
+	assert.Equal (t, 400, errObj.StatusCode)
+	assert.Equal (t, "HouseCall Error : 400 : Archived job : job_1 : ", errObj.Error())
+}
+
+func TestFirstModelsValidationError (t *testing.T) {
+	for _, body := range []string{
+		`{"error":{"message":"Validation failed","errors":{"first_name":["can't be blank"],"email":["is invalid","is taken"]}}}`,
+		`{"errors":{"first_name":"can't be blank","email":["is invalid","is taken"]}}`,
+		`{"error":{"details":[{"field":"first_name","message":"can't be blank"},{"field":"email","message":"is invalid"},{"field":"email","message":"is taken"}]}}`,
+	} {
+		errObj := &Error{}
+		err := json.Unmarshal ([]byte(body), errObj)
+		if err != nil { t.Fatal (err) }
+		errObj.StatusCode = 422
+
+		err = errObj.Err("")
+		assert.Equal (t, true, errors.Is (err, ErrValidation), body)
+		assert.Equal (t, ErrValidation, errors.Cause (err), body)
+
+		var vErr *ValidationError
+		if !errors.As (err, &vErr) { t.Fatal (body) }
+
+		assert.Equal (t, "can't be blank", vErr.Field ("first_name"), body)
+		assert.Equal (t, []string{"is invalid", "is taken"}, vErr.Fields["email"], body)
+		assert.Equal (t, "", vErr.Field ("last_name"), body)
+		assert.Equal (t, "Validation failed", errObj.ErrMsg, body)
+		assert.Contains (t, err.Error(), "email is invalid, is taken; first_name can't be blank", body)
+
+		// still get to the status code
+		var hcErr *Error
+		assert.Equal (t, true, errors.As (err, &hcErr), body)
+		assert.Equal (t, 422, hcErr.StatusCode, body)
+	}
+}
+
 func TestFirstModelsOAuthResponse (t *testing.T) {
 	resp := OauthResponse {
 		Expires: 1000,
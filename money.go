@@ -0,0 +1,104 @@
+/** ****************************************************************************************************************** **
+    Money
+    HCP sends all its amounts as whole cents, so 1234.50 comes over the wire as 123450.
+    Keeping them as cents means adding up a report never picks up floating point rounding errors.
+
+** ****************************************************************************************************************** **/
+
+package housecall
+
+import (
+    "github.com/pkg/errors"
+
+    "encoding/json"
+    "math"
+    "strconv"
+)
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- STRUCTS ---------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// an amount in cents, marshals to and from json as the same plain number HCP uses
+type Money int64
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- FUNCTIONS -------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// converts a dollar amount into cents, rounding to the nearest cent
+func Dollars (amount float64) Money {
+    return Money(math.Round (amount * 100))
+}
+
+// reads the amount as a number of cents
+// a whole number written with a decimal, like 1999.0, is fine. Anything with actual fractions of a cent is an error,
+// since it's most likely dollars and guessing would be off by 100x
+func (this *Money) UnmarshalJSON (b []byte) error {
+    if string(b) == "null" { return nil } // leave it alone
+
+    if cents, err := strconv.ParseInt (string(b), 10, 64); err == nil {
+        *this = Money(cents)
+        return nil
+    }
+
+    cents, err := strconv.ParseFloat (string(b), 64)
+    if err != nil { return errors.Wrapf (err, "not a money amount : %s", string(b)) }
+    if cents != math.Trunc (cents) || math.Abs (cents) >= math.MaxInt64 { 
+        return errors.Errorf ("money amount isn't a whole number of cents : %s", string(b)) 
+    }
+
+    *this = Money(cents)
+    return nil
+}
+
+// just to be clear that we're always writing out the number of cents
+func (this Money) MarshalJSON () ([]byte, error) {
+    return json.Marshal (int64(this))
+}
+
+func (this Money) Cents () int64 { return int64(this) }
+
+// returns the amount in dollars, only use this for display since it's a float
+func (this Money) Dollars () float64 { return float64(this) / 100 }
+
+func (this Money) Add (amount Money) Money { return this + amount }
+
+func (this Money) Sub (amount Money) Money { return this - amount }
+
+// returns the amount multiplied by the quantity, rounded to the nearest cent
+// like a line item's unit price times how many there are
+func (this Money) Times (quantity float64) Money {
+    return Money(math.Round (float64(this) * quantity))
+}
+
+// adds up all the amounts
+func SumMoney (amounts ...Money) (ret Money) {
+    for _, amount := range amounts {
+        ret += amount
+    }
+    return
+}
+
+// returns the amount for display, like $1,234.50 or -$12.05
+func (this Money) Format () string {
+    cents := uint64(this) // as unsigned so the smallest int64 still has room once it's flipped
+    sign := ""
+    if this < 0 {
+        sign = "-"
+        cents = -cents
+    }
+
+    dollars := strconv.FormatUint (cents / 100, 10)
+
+    // add in our commas every 3 digits from the right
+    var withCommas []byte
+    for i := range dollars {
+        if i > 0 && (len(dollars) - i) % 3 == 0 { withCommas = append (withCommas, ',') }
+        withCommas = append (withCommas, dollars[i])
+    }
+
+    return sign + "$" + string(withCommas) + "." + strconv.FormatUint (cents % 100 / 10, 10) + strconv.FormatUint (cents % 10, 10)
+}
+
+func (this Money) String () string { return this.Format() }
@@ -0,0 +1,56 @@
+package housecall
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"testing"
+	"math"
+	"encoding/json"
+)
+
+func TestFirstMoneyFormat (t *testing.T) {
+	assert.Equal (t, "$0.00", Money(0).Format())
+	assert.Equal (t, "$0.05", Money(5).Format())
+	assert.Equal (t, "$12.50", Money(1250).Format())
+	assert.Equal (t, "$1,234.50", Money(123450).Format())
+	assert.Equal (t, "$123,456.78", Money(12345678).Format())
+	assert.Equal (t, "$1,234,567.00", Money(123456700).Format())
+	assert.Equal (t, "-$1,234.05", Money(-123405).Format())
+	assert.Equal (t, "-$92,233,720,368,547,758.08", Money(math.MinInt64).Format(), "the smallest one can't be flipped as an int64")
+	assert.Equal (t, "$92,233,720,368,547,758.07", Money(math.MaxInt64).Format())
+	assert.Equal (t, "$1,234.50", Dollars (1234.5).String())
+}
+
+func TestFirstMoneyMath (t *testing.T) {
+	// the classic 0.1 + 0.2, in cents it's exact
+	assert.Equal (t, Money(30), Dollars (0.1).Add (Dollars (0.2)))
+	assert.Equal (t, Money(-50), Money(100).Sub (150))
+	assert.Equal (t, Money(600), SumMoney (100, 200, 300))
+	assert.Equal (t, Money(3750), Money(2500).Times (1.5))
+	assert.Equal (t, Money(333), Money(1000).Times (1.0 / 3), "rounds to the nearest cent")
+	assert.Equal (t, 12.5, Money(1250).Dollars())
+}
+
+func TestFirstMoneyJSON (t *testing.T) {
+	var job Job
+	err := json.Unmarshal ([]byte(`{"total_amount":123450,"outstanding_balance":2500}`), &job)
+	if err != nil { t.Fatal (err) }
+
+	assert.Equal (t, Money(123450), job.Total)
+	assert.Equal (t, Money(2500), job.Balance)
+
+	err = json.Unmarshal ([]byte(`{"total_amount":1999.0,"outstanding_balance":null}`), &job)
+	if err != nil { t.Fatal (err) }
+	assert.Equal (t, Money(1999), job.Total)
+
+	// goes back out the same way it came in
+	data, err := json.Marshal (LineItem { UnitPrice: 12500, UnitCost: 4000 })
+	if err != nil { t.Fatal (err) }
+	assert.Contains (t, string(data), `"unit_price":12500`)
+	assert.Contains (t, string(data), `"unit_cost":4000`)
+
+	assert.NotEqual (t, nil, json.Unmarshal ([]byte(`{"total_amount":"abc"}`), &job))
+	assert.NotEqual (t, nil, json.Unmarshal ([]byte(`{"total_amount":"1999"}`), &job), "has to be a number")
+	assert.NotEqual (t, nil, json.Unmarshal ([]byte(`{"total_amount":19.99}`), &job), "that's dollars, not cents")
+	assert.NotEqual (t, nil, json.Unmarshal ([]byte(`{"total_amount":1e30}`), &job), "too big")
+}
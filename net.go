@@ -13,22 +13,25 @@ import (
     "net/http"
     "context"
     "encoding/json"
+    "io"
     "io/ioutil"
     "bytes"
+    "mime/multipart"
+    "net/textproto"
+    "strings"
+    "time"
 )
 
+// same as the one in mime/multipart for the file names in the form
+var quoteEscaper = strings.NewReplacer ("\\", "\\\\", `"`, "\\\"")
+
   //-----------------------------------------------------------------------------------------------------------------------//
  //----- PRIVATE ---------------------------------------------------------------------------------------------------------//
 //-----------------------------------------------------------------------------------------------------------------------//
 
-// handles making the request and reading the results from it 
+// handles reading the results from the response
 // if there's an error the Error object will be set, otherwise it will be nil
-func (this *HouseCall) finish (req *http.Request, out interface{}) (*Error, error) {
-	resp, err := http.DefaultClient.Do (req)
-	
-	if err != nil { return nil, errors.WithStack (err) }
-	defer resp.Body.Close()
-
+func (this *HouseCall) finish (resp *http.Response, out interface{}) (*Error, error) {
 	body, _ := ioutil.ReadAll (resp.Body)
 
 	if resp.StatusCode == http.StatusGone {
@@ -43,6 +46,7 @@ func (this *HouseCall) finish (req *http.Request, out interface{}) (*Error, erro
 		errObj := &Error{}
 		errObj.ErrMsg = string(body) // dump the whole body in here
         errObj.StatusCode = resp.StatusCode // if it didn't get an error code, set it
+		errObj.retryAfter = parseRetryAfter (resp.Header.Get ("Retry-After"))
 		
         return errObj, nil
 
@@ -53,14 +57,61 @@ func (this *HouseCall) finish (req *http.Request, out interface{}) (*Error, erro
 		if errObj.StatusCode == 0 {
 			errObj.StatusCode = resp.StatusCode // if it didn't get an error code, set it
 		}
+		errObj.retryAfter = parseRetryAfter (resp.Header.Get ("Retry-After"))
 		return errObj, nil
 	}
 	
+	var err error
 	if out != nil { err = errors.WithStack (json.Unmarshal (body, out)) }
 	
 	return nil, err // we're good
 }
 
+// returns the full url for the link, links that are already a full url are left alone
+func (this *HouseCall) url (link string) string {
+	if strings.HasPrefix (link, "https://") || strings.HasPrefix (link, "http://") { return link }
+	return fmt.Sprintf ("%s/%s", this.baseURL, link)
+}
+
+// builds the request with our headers, an error here means the link is bad and trying again won't help
+func (this *HouseCall) request (ctx context.Context, requestType, link string, header map[string]string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext (ctx, requestType, this.url (link), body)
+	if err != nil { return nil, errors.Wrap (err, link) }
+
+	for key, val := range header { req.Header.Set (key, val) }
+	if len(this.userAgent) > 0 { req.Header.Set ("User-Agent", this.userAgent) }
+
+	return req, nil
+}
+
+// waits our turn and then makes the request, the caller needs to close the response body
+// full urls to somewhere other than HCP, like where attachments are stored, aren't limited
+func (this *HouseCall) do (ctx context.Context, req *http.Request) (*http.Response, error) {
+	// wait our turn, each access token gets its own limit
+	if strings.HasPrefix (req.URL.String(), this.baseURL + "/") {
+		if err := this.limiter.wait (ctx, req.Header.Get ("Authorization")); err != nil { return nil, err }
+	}
+
+	resp, err := this.client.Do (req)
+	return resp, errors.WithStack (err)
+}
+
+// makes a single request to HCP
+// the bool returned is true if this failed in a way that's worth trying again
+func (this *HouseCall) attempt (ctx context.Context, requestType, link string, header map[string]string,
+						body []byte, out interface{}) (*Error, bool, error) {
+
+	req, err := this.request (ctx, requestType, link, header, bytes.NewReader(body))
+	if err != nil { return nil, false, err } // a bad link stays bad
+
+	resp, err := this.do (ctx, req)
+	if err != nil { return nil, resp == nil && ctx.Err() == nil, err } // network errors are worth a retry, as long as we weren't cancelled
+	defer resp.Body.Close()
+
+	errObj, err := this.finish (resp, out)
+	return errObj, errObj.retryable(), err
+}
+
   //-----------------------------------------------------------------------------------------------------------------------//
  //----- FUNCTIONS -------------------------------------------------------------------------------------------------------//
 //-----------------------------------------------------------------------------------------------------------------------//
@@ -77,11 +128,79 @@ func (this *HouseCall) send (ctx context.Context, requestType, link string, head
 		header["Content-Type"] = "application/json; charset=utf-8"
 	}
 	
-	req, err := http.NewRequestWithContext (ctx, requestType, fmt.Sprintf ("%s/%s", apiURL, link), bytes.NewBuffer(jstr))
-	if err != nil { return nil, errors.Wrap (err, link) }
+	for i := 1; ; i++ { // keep going until we succeed or our retry policy says to stop
+		errObj, retry, err := this.attempt (ctx, requestType, link, header, jstr, out)
 
-	for key, val := range header { req.Header.Set (key, val) }
-	errObj, err := this.finish (req, out)
-	
-	return errObj, errors.Wrapf (err, " %s : %s", link, string(jstr))
+		if retry && this.retry.allowed (requestType, i) {
+			var wait time.Duration
+			if errObj != nil { wait = errObj.retryAfter }
+
+			if delay, ok := this.retry.delay (i, wait); ok && sleepCtx (ctx, delay) { continue } // try again
+		}
+
+		return errObj, errors.Wrapf (err, " %s : %s", link, string(jstr))
+	}
+}
+
+// sends a multipart form with a single file in it, the file is streamed as it's read so it's never all in memory
+// since we can't read the file again, this is never retried
+func (this *HouseCall) sendFile (ctx context.Context, link string, header map[string]string, fieldName, fileName, contentType string,
+						file io.Reader, out interface{}) (*Error, error) {
+
+	pr, pw := io.Pipe()
+	defer pr.Close() // if the request stops early, this stops our writer too
+
+	form := multipart.NewWriter (pw)
+	header["Content-Type"] = form.FormDataContentType()
+
+	req, err := this.request (ctx, http.MethodPost, link, header, pr)
+	if err != nil { return nil, errors.Wrapf (err, " %s : %s", link, fileName) }
+
+	go func () {
+		part := make(textproto.MIMEHeader)
+		part.Set ("Content-Disposition", fmt.Sprintf (`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace (fieldName), quoteEscaper.Replace (fileName)))
+		part.Set ("Content-Type", contentType)
+
+		w, err := form.CreatePart (part)
+		if err == nil { _, err = io.Copy (w, file) }
+		if err == nil { err = form.Close() }
+		pw.CloseWithError (err) // a nil error is a normal EOF for the reader
+	}()
+
+	resp, err := this.do (ctx, req)
+	if err != nil { return nil, errors.Wrapf (err, " %s : %s", link, fileName) }
+	defer resp.Body.Close()
+
+	errObj, err := this.finish (resp, out)
+	return errObj, errors.Wrapf (err, " %s : %s", link, fileName)
+}
+
+// makes a GET request and returns the body as it comes in, instead of parsing it
+// retried the same as send, the caller needs to close what's returned
+func (this *HouseCall) open (ctx context.Context, link string, header map[string]string) (io.ReadCloser, *Error, error) {
+	for i := 1; ; i++ { // keep going until we succeed or our retry policy says to stop
+		var errObj *Error
+		req, err := this.request (ctx, http.MethodGet, link, header, nil)
+		if err != nil { return nil, nil, err } // a bad link stays bad
+
+		resp, err := this.do (ctx, req)
+		retry := resp == nil && ctx.Err() == nil // network errors are worth a retry, as long as we weren't cancelled
+
+		if resp != nil {
+			if resp.StatusCode < 400 { return resp.Body, nil, nil } // we're good, they'll read it
+
+			errObj, err = this.finish (resp, nil)
+			resp.Body.Close()
+			retry = errObj.retryable()
+		}
+
+		if retry && this.retry.allowed (http.MethodGet, i) {
+			var wait time.Duration
+			if errObj != nil { wait = errObj.retryAfter }
+
+			if delay, ok := this.retry.delay (i, wait); ok && sleepCtx (ctx, delay) { continue } // try again
+		}
+
+		return nil, errObj, errors.Wrap (err, link)
+	}
 }
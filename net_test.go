@@ -0,0 +1,52 @@
+package housecall
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"testing"
+	"context"
+	"time"
+	"net/http"
+	"net/http/httptest"
+)
+
+// creates a housecall object pointed at a local test server instead of HCP
+func newTestHouseCall (t *testing.T, handler http.HandlerFunc, opts ...Option) *HouseCall {
+	srv := httptest.NewServer (handler)
+	t.Cleanup (srv.Close)
+
+	opts = append ([]Option{ WithBaseURL (srv.URL), WithHTTPClient (srv.Client()) }, opts...)
+	hc, err := NewHouseCall ("ca96e4cd990507c2995b9633bd9caa679bee26e99f98572ba54751ab4ff24886",
+		"1fd00f12ab1d3d13c6bf746aa1868bd591af098100d800195b56b6fa97795d73", "https://google.com", opts...)
+	if err != nil { t.Fatal (err) }
+
+	return hc
+}
+
+func TestFirstNetOptions (t *testing.T) {
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		assert.Equal (t, "/company", r.URL.Path)
+		assert.Equal (t, "Bearer token", r.Header.Get ("Authorization"))
+		assert.Equal (t, "beeline-test", r.Header.Get ("User-Agent"))
+
+		w.Write ([]byte(companyTest1))
+	}, WithUserAgent ("beeline-test"))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	company, err := hc.Company (ctx, "token")
+	if err != nil { t.Fatal (err) }
+
+	assert.Equal (t, "Comrade Brewing Company", company.Name, "name")
+}
+
+func TestFirstNetBadOptions (t *testing.T) {
+	_, err := NewHouseCall ("ca96e4cd990507c2995b9633bd9caa679bee26e99f98572ba54751ab4ff24886",
+		"1fd00f12ab1d3d13c6bf746aa1868bd591af098100d800195b56b6fa97795d73", "https://google.com", WithBaseURL ("not a url"))
+	assert.NotEqual (t, nil, err, "should have errored")
+
+	_, err = NewHouseCall ("ca96e4cd990507c2995b9633bd9caa679bee26e99f98572ba54751ab4ff24886",
+		"1fd00f12ab1d3d13c6bf746aa1868bd591af098100d800195b56b6fa97795d73", "https://google.com", WithHTTPClient (nil))
+	assert.NotEqual (t, nil, err, "should have errored")
+}
@@ -0,0 +1,57 @@
+/** ****************************************************************************************************************** **
+    Options for configuring the HouseCall object
+    Passed to NewHouseCall, each one tweaks how we talk to HCP
+
+** ****************************************************************************************************************** **/
+
+package housecall
+
+import (
+    "github.com/pkg/errors"
+
+    "net/http"
+    "net/url"
+    "strings"
+)
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- STRUCTS ---------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// Option changes the default setup of a HouseCall object when passed to NewHouseCall
+type Option func (*HouseCall) error
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- FUNCTIONS -------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// use this http client for all requests, allows for setting timeouts, proxies, tls, etc
+func WithHTTPClient (client *http.Client) Option {
+    return func (this *HouseCall) error {
+        if client == nil { return errors.Errorf ("http client can't be nil") }
+
+        this.client = client
+        return nil
+    }
+}
+
+// sends all requests to this url instead of the live HCP api
+// useful for pointing at a local stand-in server when testing
+func WithBaseURL (baseURL string) Option {
+    return func (this *HouseCall) error {
+        u, err := url.Parse (baseURL)
+        if err != nil { return errors.Wrapf (err, "%s is not a valid url", baseURL) }
+        if u.Scheme == "" || u.Host == "" { return errors.Errorf ("%s is not a valid url", baseURL) }
+
+        this.baseURL = strings.TrimRight (baseURL, "/") // we add the slash when we build the link
+        return nil
+    }
+}
+
+// sets the User-Agent header on every request we send
+func WithUserAgent (userAgent string) Option {
+    return func (this *HouseCall) error {
+        this.userAgent = userAgent
+        return nil
+    }
+}
@@ -0,0 +1,362 @@
+/** ****************************************************************************************************************** **
+    Paging through lists
+    HCP returns lists one page at a time, these iterators handle requesting the next page as you go.
+    Stops with ErrTooManyRecords if there's more than the Max records you asked for, after handing out the first Max.
+    Set Workers to request the pages at the same time once we know how many there are, ~3 seconds per page adds up.
+
+    iter := hc.IterateJobs (ctx, token, ListJobsOptions { WorkStatuses: []WorkStatusFilter{ WorkStatusFilter_scheduled } })
+    for iter.Next() {
+        job := iter.Item()
+    }
+    if err := iter.Err(); err != nil { ... }
+
+** ****************************************************************************************************************** **/
+
+package housecall
+
+import (
+    "github.com/pkg/errors"
+
+    "context"
+    "fmt"
+    "net/http"
+    "net/url"
+    "strconv"
+    "sync"
+)
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- STRUCTS ---------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// controls how we page through a list
+type PageOptions struct {
+    PageSize int // records per request, 0 uses the default for that list. HCP won't go over 200
+    Max int // the most records to return, 0 means no limit
+    Workers int // after the first page, how many of the remaining pages to request at once. 0 or 1 goes one at a time
+}
+
+// the list responses from HCP all look about the same
+type pageList interface {
+    pages () int // total pages HCP says there are
+    items () int // total records HCP says there are, 0 if it doesn't say
+    length () int // records on this page
+}
+
+type pager struct {
+    hc *HouseCall
+    ctx context.Context
+    header map[string]string
+    link string // without the params
+    params url.Values
+    max, pageSize, workers int
+    checkTotal bool // error out before handing out anything if HCP says there's more than max
+    newPage func () pageList // creates the right response type for this list
+
+    page int // last page we requested
+    current pageList // what we're iterating through right now
+    queue []pageList // pages we've already requested concurrently, in order
+    idx int // where we are in the current page
+    seen int // total records we've handed out
+    done bool
+    err error
+}
+
+type JobIterator struct { pager }
+type EstimateIterator struct { pager }
+type CustomerIterator struct { pager }
+type EmployeeIterator struct { pager }
+type EventIterator struct { pager }
+type LeadSourceIterator struct { pager }
+type InvoiceIterator struct { pager }
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- PRIVATE FUNCTIONS -----------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+func (this *jobListResponse) pages () int { return this.TotalPages }
+func (this *jobListResponse) items () int { return this.TotalItems }
+func (this *jobListResponse) length () int { return len(this.Jobs) }
+
+func (this *estimateListResponse) pages () int { return this.TotalPages }
+func (this *estimateListResponse) items () int { return this.TotalItems }
+func (this *estimateListResponse) length () int { return len(this.Estimates) }
+
+func (this *customerListResponse) pages () int { return this.TotalPages }
+func (this *customerListResponse) items () int { return this.TotalItems }
+func (this *customerListResponse) length () int { return len(this.Customers) }
+
+func (this *employeeListResponse) pages () int { return this.TotalPages }
+func (this *employeeListResponse) items () int { return this.TotalItems }
+func (this *employeeListResponse) length () int { return len(this.Employees) }
+
+func (this *eventListResponse) pages () int { return this.TotalPages }
+func (this *eventListResponse) items () int { return this.TotalItems }
+func (this *eventListResponse) length () int { return len(this.Events) }
+
+func (this *leadResponse) pages () int { return this.Total_pages }
+func (this *leadResponse) items () int { return 0 } // not included
+func (this *leadResponse) length () int { return len(this.Lead_sources) }
+
+func (this *invoiceListResponse) pages () int { return this.TotalPages }
+func (this *invoiceListResponse) items () int { return this.TotalItems }
+func (this *invoiceListResponse) length () int { return len(this.Invoices) }
+
+func (this *HouseCall) newPager (ctx context.Context, token, link string, params url.Values, opts PageOptions,
+                                defaultSize int, newPage func () pageList) pager {
+    header := make(map[string]string)
+    header["Authorization"] = "Bearer " + token
+
+    if params == nil { params = url.Values{} }
+    if opts.PageSize <= 0 { opts.PageSize = defaultSize }
+    params.Set("page_size", strconv.Itoa(opts.PageSize))
+
+    return pager {
+        hc: this,
+        ctx: ctx,
+        header: header,
+        link: link,
+        params: params,
+        max: opts.Max,
+        pageSize: opts.PageSize,
+        workers: opts.Workers,
+        newPage: newPage,
+    }
+}
+
+// requests the next page from HCP
+func (this *pager) fetch () bool {
+    this.page++
+    this.params.Set("page", strconv.Itoa(this.page)) // set our next page
+
+    resp := this.newPage()
+
+    errObj, err := this.hc.send (this.ctx, http.MethodGet, fmt.Sprintf("%s?%s", this.link, this.params.Encode()), this.header, nil, resp)
+    if err != nil { this.err = errors.WithStack(err) } // bail
+    if errObj != nil { this.err = errObj.Err(this.link) } // something else bad
+
+    if this.err != nil {
+        this.done = true
+        return false
+    }
+
+    // if HCP tells us there's too many, no sense in requesting them all to find out
+    if this.checkTotal && this.max > 0 && resp.items() > this.max {
+        this.err = errors.Wrapf (ErrTooManyRecords, "%d records from %s is over %d", resp.items(), this.link, this.max)
+        this.done = true
+        return false
+    }
+
+    // we're here, we're good
+    this.current = resp
+    this.idx = 0
+    return true
+}
+
+// requests the rest of the pages at the same time, at most workers at once
+// the first error cancels the requests still going
+// if there's a max, we only request enough pages to know if we went over it
+func (this *pager) fetchRest () bool {
+    last := this.current.pages()
+    if this.max > 0 {
+        if need := this.max / this.pageSize + 1; need < last { last = need }
+    }
+
+    count := last - this.page
+    if count < 2 { return this.fetch() } // nothing to gain here
+
+    // build our links now, the params aren't safe to share
+    links := make([]string, count)
+    for i := range links {
+        this.params.Set("page", strconv.Itoa(this.page + 1 + i))
+        links[i] = fmt.Sprintf("%s?%s", this.link, this.params.Encode())
+    }
+
+    ctx, cancel := context.WithCancel (this.ctx)
+    defer cancel()
+
+    results := make([]pageList, count)
+    var firstErr error
+    var errLock sync.Mutex
+    var wg sync.WaitGroup
+
+    idxs := make(chan int)
+    workers := this.workers
+    if workers > count { workers = count }
+
+    for w := 0; w < workers; w++ {
+        wg.Add (1)
+        go func () {
+            defer wg.Done()
+
+            for i := range idxs {
+                resp := this.newPage()
+
+                errObj, err := this.hc.send (ctx, http.MethodGet, links[i], this.header, nil, resp)
+                if err == nil && errObj != nil { err = errObj.Err(this.link) } // something else bad
+
+                if err != nil {
+                    errLock.Lock()
+                    if firstErr == nil { 
+                        firstErr = errors.WithStack(err)
+                        cancel() // stop everyone else
+                    }
+                    errLock.Unlock()
+                    continue
+                }
+
+                results[i] = resp // each goroutine has its own index, so this is safe
+            }
+        }()
+    }
+
+    // hand out the pages, unless we've already failed
+    for i := range links {
+        select {
+        case idxs <- i:
+        case <-ctx.Done():
+        }
+    }
+    close (idxs)
+    wg.Wait()
+
+    if firstErr == nil && this.ctx.Err() != nil { firstErr = errors.WithStack (this.ctx.Err()) } // cancelled before we got them all
+    if firstErr != nil {
+        this.err = firstErr
+        this.done = true
+        return false
+    }
+
+    // we're here, we're good
+    this.page = last
+    this.queue = results
+    return true
+}
+
+// moves to the next record, requesting the next page if we need to
+// returns false when we're out of records, or there was an error
+func (this *pager) next () bool {
+    if this.done { return false }
+
+    if this.current != nil { this.idx++ } // move past the one we already handed out
+
+    for this.current == nil || this.idx >= this.current.length() {
+        if len(this.queue) > 0 { // we already have the next page
+            this.current, this.queue = this.queue[0], this.queue[1:]
+            this.idx = 0
+            continue
+        }
+
+        if this.current != nil && this.page >= this.current.pages() { // we finished
+            this.done = true
+            return false
+        }
+
+        if this.current != nil && this.workers > 1 { // we know how many pages there are now
+            if !this.fetchRest() { return false }
+        } else if !this.fetch() { return false }
+    }
+
+    // make sure we're not going over what they wanted
+    if this.max > 0 && this.seen >= this.max {
+        this.err = errors.Wrapf (ErrTooManyRecords, "received over %d records from %s", this.max, this.link)
+        this.done = true
+        return false
+    }
+
+    this.seen++
+    return true
+}
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- FUNCTIONS -------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+//----- ITERATORS
+
+func (this *JobIterator) Next () bool { return this.next() }
+func (this *JobIterator) Item () *Job { return this.current.(*jobListResponse).Jobs[this.idx] }
+func (this *JobIterator) Err () error { return this.err }
+
+func (this *EstimateIterator) Next () bool { return this.next() }
+func (this *EstimateIterator) Item () Estimate { return this.current.(*estimateListResponse).Estimates[this.idx] }
+func (this *EstimateIterator) Err () error { return this.err }
+
+func (this *CustomerIterator) Next () bool { return this.next() }
+func (this *CustomerIterator) Item () Customer { return this.current.(*customerListResponse).Customers[this.idx] }
+func (this *CustomerIterator) Err () error { return this.err }
+
+func (this *EmployeeIterator) Next () bool { return this.next() }
+func (this *EmployeeIterator) Item () Employee { return this.current.(*employeeListResponse).Employees[this.idx] }
+func (this *EmployeeIterator) Err () error { return this.err }
+
+func (this *EventIterator) Next () bool { return this.next() }
+func (this *EventIterator) Item () Event { return this.current.(*eventListResponse).Events[this.idx] }
+func (this *EventIterator) Err () error { return this.err }
+
+func (this *LeadSourceIterator) Next () bool { return this.next() }
+func (this *LeadSourceIterator) Item () *LeadSource { return this.current.(*leadResponse).Lead_sources[this.idx] }
+func (this *LeadSourceIterator) Err () error { return this.err }
+
+func (this *InvoiceIterator) Next () bool { return this.next() }
+func (this *InvoiceIterator) Item () Invoice { return this.current.(*invoiceListResponse).Invoices[this.idx] }
+func (this *InvoiceIterator) Err () error { return this.err }
+
+//----- LISTS
+
+// the lists return the records they got along with ErrTooManyRecords, so this says when to keep them
+// for any other error they return nothing
+func tooMany (err error) bool {
+    return errors.Cause (err) == ErrTooManyRecords
+}
+
+// used by the list calls for jobs, estimates and customers which can be long
+const defaultPageWorkers = 4
+
+// pages through the jobs matching our filters
+func (this *HouseCall) IterateJobs (ctx context.Context, token string, opts ListJobsOptions) *JobIterator {
+    return &JobIterator { this.newPager (ctx, token, "jobs", opts.params(), opts.PageOptions, 200, func () pageList { return &jobListResponse{} }) }
+}
+
+// pages through the estimates matching our filters
+func (this *HouseCall) IterateEstimates (ctx context.Context, token string, opts ListEstimatesOptions) *EstimateIterator {
+    return &EstimateIterator { this.newPager (ctx, token, "estimates", opts.params(), opts.PageOptions, 200, func () pageList { return &estimateListResponse{} }) }
+}
+
+// pages through the customers matching the search, most recently created first
+// an empty search returns all of them
+func (this *HouseCall) IterateCustomers (ctx context.Context, token, search string, opts PageOptions) *CustomerIterator {
+    params := url.Values{}
+    params.Set("sort_direction", "desc")
+    params.Set("sort_by", "created_at")
+    params.Set("q", search)
+
+    return &CustomerIterator { this.newPager (ctx, token, "customers", params, opts, 200, func () pageList { return &customerListResponse{} }) }
+}
+
+// pages through the employees (pros) in asc by last name
+func (this *HouseCall) IterateEmployees (ctx context.Context, token string, opts PageOptions) *EmployeeIterator {
+    params := url.Values{}
+    params.Set("sort_direction", "asc")
+    params.Set("sort_by", "last_name")
+
+    return &EmployeeIterator { this.newPager (ctx, token, "employees", params, opts, 100, func () pageList { return &employeeListResponse{} }) }
+}
+
+// pages through all the events, most recent first
+func (this *HouseCall) IterateEvents (ctx context.Context, token string, opts PageOptions) *EventIterator {
+    params := url.Values{}
+    params.Set("sort_direction", "desc")
+
+    return &EventIterator { this.newPager (ctx, token, "events", params, opts, 200, func () pageList { return &eventListResponse{} }) }
+}
+
+// pages through the lead sources for an org
+func (this *HouseCall) IterateLeads (ctx context.Context, token string, opts PageOptions) *LeadSourceIterator {
+    return &LeadSourceIterator { this.newPager (ctx, token, "lead_sources", nil, opts, 100, func () pageList { return &leadResponse{} }) }
+}
+
+// pages through the invoices matching our filters
+func (this *HouseCall) IterateInvoices (ctx context.Context, token string, opts ListInvoicesOptions) *InvoiceIterator {
+    return &InvoiceIterator { this.newPager (ctx, token, "invoices", opts.params(), opts.PageOptions, 200, func () pageList { return &invoiceListResponse{} }) }
+}
@@ -0,0 +1,206 @@
+package housecall
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/pkg/errors"
+
+	"testing"
+	"context"
+	"time"
+	"fmt"
+	"strconv"
+	"strings"
+	"net/http"
+	"sync/atomic"
+)
+
+// serves pages of jobs, 2 per page, named after the page they came from
+func jobPagesHandler (t *testing.T, totalPages int) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request) {
+		assert.Equal (t, "/jobs", r.URL.Path)
+		assert.Equal (t, "2", r.URL.Query().Get ("page_size"))
+
+		page, _ := strconv.Atoi (r.URL.Query().Get ("page"))
+		fmt.Fprintf (w, `{"page":%d,"total_pages":%d,"jobs":[{"id":"job_%d_a"},{"id":"job_%d_b"}]}`, page, totalPages, page, page)
+	}
+}
+
+func TestFirstPagerJobs (t *testing.T) {
+	hc := newTestHouseCall (t, jobPagesHandler (t, 3))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	var ids []string
+	iter := hc.IterateJobs (ctx, "token", ListJobsOptions { PageOptions: PageOptions { PageSize: 2 } })
+	for iter.Next() {
+		ids = append (ids, iter.Item().Id)
+	}
+	if iter.Err() != nil { t.Fatal (iter.Err()) }
+
+	assert.Equal (t, []string{"job_1_a", "job_1_b", "job_2_a", "job_2_b", "job_3_a", "job_3_b"}, ids)
+}
+
+func TestFirstPagerMax (t *testing.T) {
+	hc := newTestHouseCall (t, jobPagesHandler (t, 3))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	// more records than we want
+	var ids []string
+	iter := hc.IterateJobs (ctx, "token", ListJobsOptions { PageOptions: PageOptions { PageSize: 2, Max: 3 } })
+	for iter.Next() {
+		ids = append (ids, iter.Item().Id)
+	}
+
+	assert.Equal (t, ErrTooManyRecords, errors.Cause (iter.Err()))
+	assert.Equal (t, []string{"job_1_a", "job_1_b", "job_2_a"}, ids)
+
+	// exactly what we want is fine
+	ids = nil
+	iter = hc.IterateJobs (ctx, "token", ListJobsOptions { PageOptions: PageOptions { PageSize: 2, Max: 6 } })
+	for iter.Next() {
+		ids = append (ids, iter.Item().Id)
+	}
+
+	assert.Equal (t, nil, iter.Err())
+	assert.Equal (t, 6, len(ids))
+}
+
+func TestFirstPagerError (t *testing.T) {
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get ("page") == "2" {
+			w.WriteHeader (http.StatusUnauthorized)
+			return
+		}
+		w.Write ([]byte(`{"total_pages":2,"lead_sources":[{"id":"ls_1","name":"Google"}]}`))
+	})
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	leads, err := hc.ListLeads (ctx, "token")
+	assert.Equal (t, true, errors.Is (err, ErrAuthExpired))
+	assert.Equal (t, 0, len(leads))
+}
+
+func TestFirstPagerEmpty (t *testing.T) {
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		w.Write ([]byte(`{"total_pages":0,"total_items":0,"employees":[]}`))
+	})
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	employees, err := hc.ListEmployees (ctx, "token")
+	if err != nil { t.Fatal (err) }
+	assert.Equal (t, 0, len(employees))
+}
+
+func TestFirstPagerWorkers (t *testing.T) {
+	var inFlight, most int32
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		now := atomic.AddInt32 (&inFlight, 1)
+		defer atomic.AddInt32 (&inFlight, -1)
+		for {
+			prev := atomic.LoadInt32 (&most)
+			if now <= prev || atomic.CompareAndSwapInt32 (&most, prev, now) { break }
+		}
+
+		// later pages come back faster, so they'd be out of order if we didn't sort them
+		page, _ := strconv.Atoi (r.URL.Query().Get ("page"))
+		time.Sleep (time.Millisecond * time.Duration (50 - page * 4))
+		fmt.Fprintf (w, `{"page":%d,"total_pages":10,"jobs":[{"id":"job_%d_a"},{"id":"job_%d_b"}]}`, page, page, page)
+	})
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	var ids []string
+	iter := hc.IterateJobs (ctx, "token", ListJobsOptions { PageOptions: PageOptions { PageSize: 2, Workers: 3 } })
+	for iter.Next() {
+		ids = append (ids, iter.Item().Id)
+	}
+	if iter.Err() != nil { t.Fatal (iter.Err()) }
+
+	assert.Equal (t, 20, len(ids))
+	for i, id := range ids {
+		assert.Equal (t, fmt.Sprintf ("job_%d_%s", i / 2 + 1, []string{"a", "b"}[i % 2]), id, "should be in page order")
+	}
+	assert.Equal (t, true, atomic.LoadInt32 (&most) > 1, "should have gone concurrently")
+	assert.Equal (t, true, atomic.LoadInt32 (&most) <= 3, "but no more than our workers")
+}
+
+func TestFirstPagerWorkersError (t *testing.T) {
+	var calls int32
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32 (&calls, 1)
+		page, _ := strconv.Atoi (r.URL.Query().Get ("page"))
+		if page == 2 {
+			w.WriteHeader (http.StatusNotFound)
+			return
+		}
+		if page > 2 {
+			select { // hang until we're cancelled
+			case <-r.Context().Done():
+			case <-time.After (time.Second * 3):
+			}
+		}
+		fmt.Fprintf (w, `{"page":%d,"total_pages":50,"jobs":[{"id":"job_%d"}]}`, page, page)
+	}, WithRetryPolicy (RetryPolicy{}))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	start := time.Now()
+	iter := hc.IterateJobs (ctx, "token", ListJobsOptions { PageOptions: PageOptions { PageSize: 1, Workers: 4 } })
+	for iter.Next() {}
+
+	assert.Equal (t, true, errors.Is (iter.Err(), ErrNotFound))
+	assert.Equal (t, true, time.Since (start) < time.Second * 2, "should have cancelled the rest")
+	assert.Equal (t, true, atomic.LoadInt32 (&calls) < 10, "shouldn't have kept requesting pages")
+}
+
+// hcp says there's way more than we want, we should still get the first ones
+func TestFirstPagerPartial (t *testing.T) {
+	var calls int32
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32 (&calls, 1)
+		page, _ := strconv.Atoi (r.URL.Query().Get ("page"))
+
+		customers := make([]string, 200)
+		for i := range customers {
+			customers[i] = fmt.Sprintf (`{"id":"cus_%d_%d"}`, page, i)
+		}
+		fmt.Fprintf (w, `{"page":%d,"total_pages":30,"total_items":6000,"customers":[%s]}`, page, strings.Join (customers, ","))
+	})
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	customers, err := hc.SearchCustomers (ctx, "token", "")
+	assert.Equal (t, ErrTooManyRecords, errors.Cause (err))
+	assert.Equal (t, 2000, len(customers), "should have the first 2000")
+	assert.Equal (t, "cus_1_0", customers[0].Id)
+	assert.Equal (t, true, atomic.LoadInt32 (&calls) <= 11, "only enough pages to know we went over")
+}
+
+// anything other than too many records returns nothing, same as the other lists
+func TestFirstPagerPartialError (t *testing.T) {
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get ("page") == "2" {
+			w.WriteHeader (http.StatusUnauthorized)
+			return
+		}
+		w.Write ([]byte(`{"page":1,"total_pages":2,"total_items":2,"employees":[{"id":"pro_1"}]}`))
+	})
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	employees, err := hc.ListEmployees (ctx, "token")
+	assert.Equal (t, true, errors.Is (err, ErrAuthExpired))
+	assert.Equal (t, 0, len(employees))
+	assert.Equal (t, true, employees == nil)
+}
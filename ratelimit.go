@@ -0,0 +1,121 @@
+/** ****************************************************************************************************************** **
+    Client side rate limiting
+    HCP throttles each company separately, so we keep a token bucket per access token.
+    Any number of goroutines can share the same HouseCall object and they'll wait their turn.
+
+** ****************************************************************************************************************** **/
+
+package housecall
+
+import (
+    "github.com/pkg/errors"
+
+    "context"
+    "sync"
+    "time"
+)
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- STRUCTS ---------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+type bucket struct {
+    tokens float64 // can go negative, that's the requests that are already waiting on this bucket
+    last time.Time // when we last topped up the tokens
+}
+
+type rateLimiter struct {
+    rate, burst float64 // tokens per second, and the most we can save up
+    buckets map[string]*bucket
+    lock sync.Mutex
+}
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- PRIVATE FUNCTIONS -----------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// adds back any tokens we've earned since the last time this bucket was used
+func (this *rateLimiter) refill (b *bucket, now time.Time) {
+    b.tokens += now.Sub(b.last).Seconds() * this.rate
+    if b.tokens > this.burst { b.tokens = this.burst }
+    b.last = now
+}
+
+// removes the buckets that have been idle long enough to be full again, they're the same as a new bucket
+func (this *rateLimiter) prune (now time.Time) {
+    for key, b := range this.buckets {
+        if b.tokens + now.Sub(b.last).Seconds() * this.rate >= this.burst {
+            delete (this.buckets, key)
+        }
+    }
+}
+
+// reserves a slot in the bucket for this key and returns how long we have to wait before using it
+func (this *rateLimiter) reserve (key string) time.Duration {
+    this.lock.Lock()
+    defer this.lock.Unlock()
+
+    now := time.Now()
+    b, ok := this.buckets[key]
+    if !ok {
+        this.prune (now) // good time to clean up, keeps us from growing forever with lots of tokens
+        b = &bucket { tokens: this.burst, last: now }
+        this.buckets[key] = b
+    }
+
+    this.refill (b, now)
+    b.tokens-- // take our slot
+
+    if b.tokens >= 0 { return 0 } // we're good to go now
+    return time.Duration (-b.tokens / this.rate * float64(time.Second))
+}
+
+// gives back a slot we reserved but never used
+func (this *rateLimiter) cancel (key string) {
+    this.lock.Lock()
+    defer this.lock.Unlock()
+
+    if b, ok := this.buckets[key]; ok {
+        b.tokens++
+        if b.tokens > this.burst { b.tokens = this.burst }
+    }
+}
+
+// blocks until we're allowed to send a request for this key, or the context is done
+func (this *rateLimiter) wait (ctx context.Context, key string) error {
+    if this == nil { return nil } // not limiting anything
+
+    wait := this.reserve (key)
+    if wait <= 0 { return nil } // no waiting required
+
+    timer := time.NewTimer (wait)
+    defer timer.Stop()
+
+    select {
+    case <-ctx.Done():
+        this.cancel (key) // let someone else have it
+        return errors.WithStack (ctx.Err())
+    case <-timer.C:
+        return nil
+    }
+}
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- FUNCTIONS -------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// limits the requests we send for each access token (company) to the requests per second
+// burst is how many requests can go out back to back before the limit kicks in
+func WithRateLimit (requestsPerSecond float64, burst int) Option {
+    return func (this *HouseCall) error {
+        if requestsPerSecond <= 0 { return errors.Errorf ("requests per second must be positive") }
+        if burst < 1 { burst = 1 }
+
+        this.limiter = &rateLimiter {
+            rate: requestsPerSecond,
+            burst: float64(burst),
+            buckets: make(map[string]*bucket),
+        }
+        return nil
+    }
+}
@@ -0,0 +1,47 @@
+package housecall
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/pkg/errors"
+
+	"testing"
+	"context"
+	"time"
+	"sync"
+)
+
+func TestFirstRateLimit (t *testing.T) {
+	limiter := &rateLimiter { rate: 20, burst: 2, buckets: make(map[string]*bucket) }
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add (1)
+		go func () {
+			defer wg.Done()
+			assert.Equal (t, nil, limiter.wait (ctx, "Bearer one"))
+		}()
+	}
+	wg.Wait()
+
+	// 2 go right away, the other 4 are 50ms apart
+	assert.Equal (t, true, time.Since (start) >= time.Millisecond * 190, "should have been throttled")
+
+	// a different token has its own bucket
+	start = time.Now()
+	assert.Equal (t, nil, limiter.wait (ctx, "Bearer two"))
+	assert.Equal (t, true, time.Since (start) < time.Millisecond * 20, "new token shouldn't wait")
+}
+
+func TestFirstRateLimitCancel (t *testing.T) {
+	limiter := &rateLimiter { rate: 0.1, burst: 1, buckets: make(map[string]*bucket) }
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Millisecond * 50)
+	defer cancel()
+
+	assert.Equal (t, nil, limiter.wait (ctx, "Bearer one"), "first one is free")
+	assert.Equal (t, context.DeadlineExceeded, errors.Cause (limiter.wait (ctx, "Bearer one")), "should give up with the context")
+}
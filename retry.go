@@ -0,0 +1,123 @@
+/** ****************************************************************************************************************** **
+    Retrying requests
+    HCP will occasionally throw a 502/503 or rate limit us with a 429, these are usually fine on a second try.
+    Only idempotent requests are retried, so we never create a job twice.
+
+** ****************************************************************************************************************** **/
+
+package housecall
+
+import (
+    "context"
+    "math/rand"
+    "net/http"
+    "strconv"
+    "time"
+)
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- STRUCTS ---------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// controls how many times and how long we wait when retrying a request
+type RetryPolicy struct {
+    MaxAttempts int // total tries, including the first. 1 or less disables retrying
+    BaseDelay time.Duration // wait before the first retry, doubles for each one after
+    MaxDelay time.Duration // cap on a single wait, 0 means no cap. A Retry-After longer than this gives up instead of waiting
+    Jitter float64 // 0 - 1, the fraction of each wait that's randomized so goroutines don't retry in lock step
+}
+
+// what every HouseCall object uses unless told otherwise
+var DefaultRetryPolicy = RetryPolicy {
+    MaxAttempts: 3,
+    BaseDelay: time.Millisecond * 500,
+    MaxDelay: time.Second * 30,
+    Jitter: 0.2,
+}
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- PRIVATE FUNCTIONS -----------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// only these methods are safe to send more than once
+func isIdempotent (method string) bool {
+    switch method {
+    case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+        return true
+    }
+    return false
+}
+
+// returns true if the status code we got back is worth another try
+func (this *Error) retryable () bool {
+    if this == nil { return false }
+
+    switch this.StatusCode {
+    case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+        return true
+    }
+    return false
+}
+
+// parses the Retry-After header, which can be either a number of seconds or an http date
+func parseRetryAfter (val string) time.Duration {
+    if len(val) == 0 { return 0 }
+
+    if secs, err := strconv.Atoi (val); err == nil && secs > 0 {
+        return time.Duration(secs) * time.Second
+    }
+
+    if tm, err := http.ParseTime (val); err == nil {
+        if wait := time.Until (tm); wait > 0 { return wait }
+    }
+    return 0 // nothing useful
+}
+
+// returns true if we're allowed another attempt after this one
+func (this RetryPolicy) allowed (method string, attempt int) bool {
+    return attempt < this.MaxAttempts && isIdempotent (method)
+}
+
+// figures out how long to wait before the next attempt
+// if the server told us how long with a Retry-After, that wins, unless it's over MaxDelay
+// then this returns false, since waiting less than they asked is just going to get rate limited again
+func (this RetryPolicy) delay (attempt int, retryAfter time.Duration) (time.Duration, bool) {
+    if retryAfter > 0 { return retryAfter, this.MaxDelay <= 0 || retryAfter <= this.MaxDelay }
+
+    wait := this.BaseDelay << uint(attempt - 1) // exponential backoff
+    if this.MaxDelay > 0 && (wait > this.MaxDelay || wait <= 0) { wait = this.MaxDelay } // also catches an overflow
+
+    if this.Jitter > 0 && wait > 0 {
+        spread := float64(wait) * this.Jitter
+        wait = time.Duration (float64(wait) - spread + (rand.Float64() * spread * 2))
+    }
+    return wait, true
+}
+
+// sleeps for the wait time, returning false if the context won't let us
+// if the deadline is going to pass before we're done waiting, don't bother
+func sleepCtx (ctx context.Context, wait time.Duration) bool {
+    if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) { return false }
+
+    timer := time.NewTimer (wait)
+    defer timer.Stop()
+
+    select {
+    case <-ctx.Done():
+        return false
+    case <-timer.C:
+        return true
+    }
+}
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- FUNCTIONS -------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// sets the retry policy for requests, use RetryPolicy{} to disable retries entirely
+func WithRetryPolicy (policy RetryPolicy) Option {
+    return func (this *HouseCall) error {
+        this.retry = policy
+        return nil
+    }
+}
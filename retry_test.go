@@ -0,0 +1,121 @@
+package housecall
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/pkg/errors"
+
+	"testing"
+	"context"
+	"time"
+	"net/http"
+	"sync/atomic"
+)
+
+var testRetryPolicy = RetryPolicy {
+	MaxAttempts: 3,
+	BaseDelay: time.Millisecond,
+	MaxDelay: time.Millisecond * 10,
+}
+
+func TestFirstRetryTransient (t *testing.T) {
+	var calls int32
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32 (&calls, 1) < 3 {
+			w.Header().Set ("Retry-After", "0")
+			w.WriteHeader (http.StatusServiceUnavailable)
+			return
+		}
+		w.Write ([]byte(companyTest1))
+	}, WithRetryPolicy (testRetryPolicy))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	company, err := hc.Company (ctx, "token")
+	if err != nil { t.Fatal (err) }
+
+	assert.Equal (t, int32(3), atomic.LoadInt32 (&calls), "should have taken 3 tries")
+	assert.Equal (t, "Comrade Brewing Company", company.Name, "name")
+}
+
+func TestFirstRetryGivesUp (t *testing.T) {
+	var calls int32
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32 (&calls, 1)
+		w.WriteHeader (http.StatusTooManyRequests)
+	}, WithRetryPolicy (testRetryPolicy))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	_, err := hc.Company (ctx, "token")
+	assert.NotEqual (t, nil, err, "should have errored")
+	assert.Equal (t, int32(3), atomic.LoadInt32 (&calls), "should have stopped at max attempts")
+}
+
+// being told to come back in an hour shouldn't block us for an hour
+func TestFirstRetryAfterTooLong (t *testing.T) {
+	var calls int32
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32 (&calls, 1)
+		w.Header().Set ("Retry-After", "3600")
+		w.WriteHeader (http.StatusTooManyRequests)
+	}, WithRetryPolicy (testRetryPolicy))
+
+	start := time.Now()
+	_, err := hc.Company (context.Background(), "token") // no deadline
+	assert.Equal (t, true, errors.Is (err, ErrRateLimited))
+	assert.Equal (t, int32(1), atomic.LoadInt32 (&calls), "should have given up right away")
+	assert.Equal (t, true, time.Since (start) < time.Second)
+}
+
+// creating things isn't safe to retry
+func TestFirstRetryPost (t *testing.T) {
+	var calls int32
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32 (&calls, 1)
+		w.WriteHeader (http.StatusBadGateway)
+	}, WithRetryPolicy (testRetryPolicy))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	err := hc.CreateCustomer (ctx, "token", &Customer{ FirstName: "Louisa" })
+	assert.NotEqual (t, nil, err, "should have errored")
+	assert.Equal (t, int32(1), atomic.LoadInt32 (&calls), "post should only be sent once")
+}
+
+// a link that can't be made into a request won't work the next time either
+func TestFirstRetryBadLink (t *testing.T) {
+	var calls int32
+	hc := newTestHouseCall (t, func (w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32 (&calls, 1)
+	}, WithRetryPolicy (RetryPolicy { MaxAttempts: 3, BaseDelay: time.Second * 10, MaxDelay: time.Second * 10 }))
+
+	start := time.Now()
+	_, err := hc.GetJob (context.Background(), "token", "job\x7f1") // control characters aren't allowed in a url
+	assert.NotEqual (t, nil, err, "should have errored")
+	assert.Equal (t, int32(0), atomic.LoadInt32 (&calls), "nothing should have been sent")
+	assert.Equal (t, true, time.Since (start) < time.Second, "shouldn't have waited to try again")
+}
+
+func TestFirstRetryDelay (t *testing.T) {
+	policy := RetryPolicy { MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Second * 3 }
+
+	delay := func (attempt int, retryAfter time.Duration) time.Duration {
+		wait, ok := policy.delay (attempt, retryAfter)
+		assert.Equal (t, true, ok)
+		return wait
+	}
+
+	assert.Equal (t, time.Second, delay (1, 0))
+	assert.Equal (t, time.Second * 2, delay (2, 0))
+	assert.Equal (t, time.Second * 3, delay (3, 0), "capped at max delay")
+	assert.Equal (t, time.Second * 2, delay (1, time.Second * 2), "retry after wins")
+
+	_, ok := policy.delay (1, time.Second * 7)
+	assert.Equal (t, false, ok, "retry after is longer than we're willing to wait")
+
+	assert.Equal (t, time.Second * 120, parseRetryAfter ("120"))
+	assert.Equal (t, time.Duration(0), parseRetryAfter ("soon"))
+}
@@ -0,0 +1,150 @@
+/** ****************************************************************************************************************** **
+    Token management
+    Holds onto the oauth tokens and refreshes them before they expire, or after HCP tells us they have.
+    Safe to share between goroutines, only one refresh happens at a time.
+
+** ****************************************************************************************************************** **/
+
+package housecall
+
+import (
+    "github.com/pkg/errors"
+
+    "context"
+    "sync"
+    "time"
+)
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- CONSTS ----------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// how close to expiring we let a token get before refreshing it
+const defaultRefreshLeeway = time.Minute * 5
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- STRUCTS ---------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+type TokenSource struct {
+    hc *HouseCall
+    oauth OauthResponse // our current tokens
+    onRefresh func (*OauthResponse) // called with the new tokens every time we refresh
+    lock sync.Mutex
+
+    // set when this came from ForCompany, tokens are loaded from and saved to the store
+    store TokenStore
+    companyId string
+
+    // how close to expiring we let the token get before refreshing it
+    // set this before sharing the TokenSource between goroutines
+    RefreshLeeway time.Duration
+}
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- PRIVATE FUNCTIONS -----------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// returns true if this token is about to expire, or already has
+// tokens without an expiration are assumed to be good until HCP tells us otherwise
+func needsRefresh (oauth *OauthResponse, leeway time.Duration) bool {
+    if len(oauth.AccessToken) == 0 { return true } // we don't have one
+    if oauth.Expires == 0 { return false } // no idea when this expires
+
+    return time.Now().Add(leeway).After (oauth.ExpiresAt())
+}
+
+// does the actual refresh, expects the lock to already be held
+func (this *TokenSource) refresh (ctx context.Context) error {
+    if len(this.oauth.RefreshToken) == 0 { return errors.Wrap (ErrAuthExpired, "no refresh token") }
+
+    oauth, err := this.hc.TokensFromRefresh (ctx, this.oauth.RefreshToken)
+    if err != nil { return err }
+
+    if oauth.Created == 0 { oauth.Created = time.Now().Unix() } // so we can track when it expires
+
+    this.oauth = *oauth // copy this over
+    if this.onRefresh != nil { this.onRefresh (oauth) } // let them save the new refresh token
+
+    if this.store != nil {
+        // the old refresh token is no good anymore, so this has to be saved
+        if err = this.store.Put (ctx, this.companyId, oauth); err != nil { return errors.Wrap (err, this.companyId) }
+    }
+    return nil
+}
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- FUNCTIONS -------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// creates a token source seeded with tokens from TokensFromCode or a previous refresh
+// onRefresh is called with the new tokens every time they're rotated, can be nil
+// onRefresh is called while the TokenSource is locked, so don't use the TokenSource from inside it
+func (this *HouseCall) NewTokenSource (oauth *OauthResponse, onRefresh func (*OauthResponse)) *TokenSource {
+    ret := &TokenSource {
+        hc: this,
+        onRefresh: onRefresh,
+        RefreshLeeway: defaultRefreshLeeway,
+    }
+    if oauth != nil { ret.oauth = *oauth }
+
+    return ret
+}
+
+// returns a copy of the current tokens
+func (this *TokenSource) Oauth () OauthResponse {
+    this.lock.Lock()
+    defer this.lock.Unlock()
+
+    return this.oauth
+}
+
+// returns a valid access token, refreshing it first if it's about to expire
+func (this *TokenSource) Token (ctx context.Context) (string, error) {
+    this.lock.Lock()
+    defer this.lock.Unlock()
+
+    if len(this.companyId) > 0 && needsRefresh (&this.oauth, this.RefreshLeeway) {
+        // check the store first, it may have newer tokens than we do
+        if err := this.load (ctx); err != nil { return "", err }
+    }
+
+    if needsRefresh (&this.oauth, this.RefreshLeeway) {
+        if err := this.refresh (ctx); err != nil { return "", err }
+    }
+
+    return this.oauth.AccessToken, nil
+}
+
+// refreshes the token, unless someone else already did since we got the stale one
+func (this *TokenSource) Refresh (ctx context.Context, stale string) (string, error) {
+    this.lock.Lock()
+    defer this.lock.Unlock()
+
+    if len(this.companyId) > 0 && this.oauth.AccessToken == stale {
+        // someone else using the same store may have already refreshed
+        if err := this.load (ctx); err != nil { return "", err }
+    }
+
+    if this.oauth.AccessToken == stale || len(stale) == 0 {
+        if err := this.refresh (ctx); err != nil { return "", err }
+    }
+
+    return this.oauth.AccessToken, nil
+}
+
+// calls fn with a valid token
+// if fn comes back with ErrAuthExpired we refresh the token and try once more
+func (this *TokenSource) Do (ctx context.Context, fn func (token string) error) error {
+    token, err := this.Token (ctx)
+    if err != nil { return err }
+
+    err = fn (token)
+    if !errors.Is (err, ErrAuthExpired) { return err } // worked, or failed for another reason
+
+    // HCP says this token is done, get another
+    token, err = this.Refresh (ctx, token)
+    if err != nil { return err }
+
+    return fn (token)
+}
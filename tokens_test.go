@@ -0,0 +1,70 @@
+package housecall
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"testing"
+	"context"
+	"time"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// fake oauth endpoint, hands out a new numbered token for every refresh
+func refreshHandler (refreshes *int32) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32 (refreshes, 1)
+		fmt.Fprintf (w, `{"access_token":"access%d","refresh_token":"refresh%d","expires_in":7200,"created_at":%d}`, n, n, time.Now().Unix())
+	}
+}
+
+func TestFirstTokenSourceRefresh (t *testing.T) {
+	var refreshes int32
+	hc := newTestHouseCall (t, refreshHandler (&refreshes))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	var saved []string
+	ts := hc.NewTokenSource (&OauthResponse { AccessToken: "old", RefreshToken: "refresh0", Expires: 7200, Created: time.Now().Add(time.Hour * -3).Unix() },
+		func (oauth *OauthResponse) { saved = append (saved, oauth.RefreshToken) })
+
+	// lots of goroutines at once, should still only refresh the one time
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add (1)
+		go func () {
+			defer wg.Done()
+			token, err := ts.Token (ctx)
+			assert.Equal (t, nil, err)
+			assert.Equal (t, "access1", token)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal (t, int32(1), atomic.LoadInt32 (&refreshes), "only 1 refresh")
+	assert.Equal (t, []string{"refresh1"}, saved, "callback should have the new refresh token")
+}
+
+func TestFirstTokenSourceDo (t *testing.T) {
+	var refreshes int32
+	hc := newTestHouseCall (t, refreshHandler (&refreshes))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	ts := hc.NewTokenSource (&OauthResponse { AccessToken: "revoked", RefreshToken: "refresh0" }, nil)
+
+	var tried []string
+	err := ts.Do (ctx, func (token string) error {
+		tried = append (tried, token)
+		if token == "revoked" { return (&Error { StatusCode: http.StatusUnauthorized }).Err("") }
+		return nil
+	})
+
+	assert.Equal (t, nil, err)
+	assert.Equal (t, []string{"revoked", "access1"}, tried, "should have refreshed and tried again")
+	assert.Equal (t, "refresh1", ts.Oauth().RefreshToken)
+}
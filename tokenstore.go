@@ -0,0 +1,166 @@
+/** ****************************************************************************************************************** **
+    Token storage
+    When we're connected to lots of HCP companies, each has its own set of tokens.
+    The store keeps them by company id so they survive restarts, and so the rotated refresh tokens are never lost.
+
+** ****************************************************************************************************************** **/
+
+package housecall
+
+import (
+    "github.com/pkg/errors"
+
+    "context"
+    "encoding/json"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "sync"
+)
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- STRUCTS ---------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// anything that can save and load tokens by company id
+// Get should return ErrTokenNotFound when it doesn't have tokens for the company
+type TokenStore interface {
+    Get (ctx context.Context, companyId string) (*OauthResponse, error)
+    Put (ctx context.Context, companyId string, oauth *OauthResponse) error
+}
+
+// keeps tokens in memory, they're lost when the process exits
+type MemoryTokenStore struct {
+    tokens map[string]OauthResponse
+    lock sync.RWMutex
+}
+
+// keeps tokens in a json file on disk, as a map of company id to tokens
+type FileTokenStore struct {
+    path string
+    lock sync.Mutex
+}
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- PRIVATE FUNCTIONS -----------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+// reads the whole file, a missing file is the same as an empty one
+func (this *FileTokenStore) read () (map[string]OauthResponse, error) {
+    ret := make(map[string]OauthResponse)
+
+    data, err := ioutil.ReadFile (this.path)
+    if os.IsNotExist (err) { return ret, nil } // nothing saved yet
+    if err != nil { return nil, errors.WithStack (err) }
+
+    if len(data) == 0 { return ret, nil }
+    if err = json.Unmarshal (data, &ret); err != nil { return nil, errors.Wrap (err, this.path) }
+
+    return ret, nil
+}
+
+// writes to a temp file first, so we never leave a half written file behind
+func (this *FileTokenStore) write (tokens map[string]OauthResponse) error {
+    data, err := json.MarshalIndent (tokens, "", "    ")
+    if err != nil { return errors.WithStack (err) }
+
+    tmp, err := ioutil.TempFile (filepath.Dir (this.path), filepath.Base (this.path) + ".*")
+    if err != nil { return errors.WithStack (err) }
+    defer os.Remove (tmp.Name()) // cleans up if we don't make it to the rename
+
+    if _, err = tmp.Write (data); err != nil {
+        tmp.Close()
+        return errors.WithStack (err)
+    }
+    if err = tmp.Close(); err != nil { return errors.WithStack (err) }
+
+    return errors.WithStack (os.Rename (tmp.Name(), this.path))
+}
+
+// pulls the tokens for our company out of the store, expects the lock to already be held
+func (this *TokenSource) load (ctx context.Context) error {
+    if this.store == nil { return errors.Errorf ("no token store set, use WithTokenStore : %s", this.companyId) }
+
+    oauth, err := this.store.Get (ctx, this.companyId)
+    if err != nil { return errors.Wrap (err, this.companyId) }
+
+    this.oauth = *oauth
+    return nil
+}
+
+  //-----------------------------------------------------------------------------------------------------------------------//
+ //----- FUNCTIONS -------------------------------------------------------------------------------------------------------//
+//-----------------------------------------------------------------------------------------------------------------------//
+
+func NewMemoryTokenStore () *MemoryTokenStore {
+    return &MemoryTokenStore { tokens: make(map[string]OauthResponse) }
+}
+
+func (this *MemoryTokenStore) Get (ctx context.Context, companyId string) (*OauthResponse, error) {
+    this.lock.RLock()
+    defer this.lock.RUnlock()
+
+    oauth, ok := this.tokens[companyId]
+    if !ok { return nil, errors.WithStack (ErrTokenNotFound) }
+
+    return &oauth, nil
+}
+
+func (this *MemoryTokenStore) Put (ctx context.Context, companyId string, oauth *OauthResponse) error {
+    this.lock.Lock()
+    defer this.lock.Unlock()
+
+    this.tokens[companyId] = *oauth
+    return nil
+}
+
+// the file is created on the first Put if it doesn't exist yet
+func NewFileTokenStore (path string) *FileTokenStore {
+    return &FileTokenStore { path: path }
+}
+
+func (this *FileTokenStore) Get (ctx context.Context, companyId string) (*OauthResponse, error) {
+    this.lock.Lock()
+    defer this.lock.Unlock()
+
+    tokens, err := this.read()
+    if err != nil { return nil, err }
+
+    oauth, ok := tokens[companyId]
+    if !ok { return nil, errors.WithStack (ErrTokenNotFound) }
+
+    return &oauth, nil
+}
+
+func (this *FileTokenStore) Put (ctx context.Context, companyId string, oauth *OauthResponse) error {
+    this.lock.Lock()
+    defer this.lock.Unlock()
+
+    tokens, err := this.read()
+    if err != nil { return err }
+
+    tokens[companyId] = *oauth
+    return this.write (tokens)
+}
+
+// stores the tokens for all requests made through HouseCall.ForCompany
+func WithTokenStore (store TokenStore) Option {
+    return func (this *HouseCall) error {
+        this.store = store
+        return nil
+    }
+}
+
+// returns the token source for a company, tokens are loaded from the token store as needed
+// and written back every time they're refreshed
+// every call for the same company returns the same TokenSource, so refreshes are never done twice
+func (this *HouseCall) ForCompany (companyId string) *TokenSource {
+    if ts, ok := this.companies.Load (companyId); ok { return ts.(*TokenSource) }
+
+    ts := this.NewTokenSource (nil, nil)
+    ts.store = this.store
+    ts.companyId = companyId
+
+    actual, _ := this.companies.LoadOrStore (companyId, ts) // someone may have beat us to it
+    return actual.(*TokenSource)
+}
@@ -0,0 +1,69 @@
+package housecall
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/pkg/errors"
+
+	"testing"
+	"context"
+	"time"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+func TestFirstFileTokenStore (t *testing.T) {
+	ctx := context.Background()
+	store := NewFileTokenStore (filepath.Join (t.TempDir(), "tokens.json"))
+
+	_, err := store.Get (ctx, "company1")
+	assert.Equal (t, ErrTokenNotFound, errors.Cause (err), "nothing saved yet")
+
+	err = store.Put (ctx, "company1", &OauthResponse { AccessToken: "access1", RefreshToken: "refresh1" })
+	if err != nil { t.Fatal (err) }
+	err = store.Put (ctx, "company2", &OauthResponse { AccessToken: "access2", RefreshToken: "refresh2" })
+	if err != nil { t.Fatal (err) }
+
+	oauth, err := store.Get (ctx, "company1")
+	if err != nil { t.Fatal (err) }
+	assert.Equal (t, "refresh1", oauth.RefreshToken)
+
+	oauth, err = store.Get (ctx, "company2")
+	if err != nil { t.Fatal (err) }
+	assert.Equal (t, "refresh2", oauth.RefreshToken)
+}
+
+func TestFirstForCompany (t *testing.T) {
+	var refreshes int32
+	store := NewMemoryTokenStore()
+	hc := newTestHouseCall (t, refreshHandler (&refreshes), WithTokenStore (store))
+
+	ctx, cancel := context.WithTimeout (context.Background(), time.Second * 5)
+	defer cancel()
+
+	// expired tokens
+	store.Put (ctx, "company1", &OauthResponse { AccessToken: "old", RefreshToken: "refresh0", Expires: 7200, Created: time.Now().Add(time.Hour * -3).Unix() })
+
+	assert.Equal (t, hc.ForCompany ("company1"), hc.ForCompany ("company1"), "should be the same handle")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add (1)
+		go func () {
+			defer wg.Done()
+			token, err := hc.ForCompany ("company1").Token (ctx)
+			assert.Equal (t, nil, err)
+			assert.Equal (t, "access1", token)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal (t, int32(1), atomic.LoadInt32 (&refreshes), "only 1 refresh")
+
+	oauth, err := store.Get (ctx, "company1")
+	if err != nil { t.Fatal (err) }
+	assert.Equal (t, "refresh1", oauth.RefreshToken, "new tokens should be saved")
+
+	_, err = hc.ForCompany ("company2").Token (ctx)
+	assert.Equal (t, ErrTokenNotFound, errors.Cause (err), "never saved")
+}